@@ -5,6 +5,101 @@ package api
 type ClassGrouping struct {
 	// Projects is a list of all project groupings for a semester
 	Projects []ProjectGrouping `json:"projects"`
+
+	// Seed is the seed of the random source used to generate the groupings,
+	// so that the same groupings can be generated again
+	Seed int64 `json:"seed"`
+
+	// Summary describes how the groupings were generated
+	Summary Summary `json:"summary"`
+}
+
+// Summary describes the outcome of generating a class grouping
+type Summary struct {
+	// Repairings is the number of times students were grouped with someone they had already collaborated with
+	Repairings int `json:"repairings"`
+
+	// Score is the value of the objective that was minimized when generating the groupings
+	Score float64 `json:"score"`
+
+	// Attempts is the number of attempts made at grouping the class
+	Attempts int `json:"attempts"`
+
+	// Partial is set when the search was stopped before it finished optimizing the groupings
+	Partial bool `json:"partial,omitempty"`
+
+	// Optimal is set when the groupings are proven to have the fewest repairings possible
+	Optimal bool `json:"optimal,omitempty"`
+
+	// LowerBound is the fewest repairings any grouping could have, as far as an exact search proved
+	LowerBound int `json:"lowerBound,omitempty"`
+
+	// Construction names the combinatorial design the groupings were built from, if any
+	Construction string `json:"construction,omitempty"`
+
+	// Workers summarize the independent generations made in parallel, if any
+	Workers []WorkerSummary `json:"workers,omitempty"`
+
+	// Repeats are the number of repeated collaborations every student has, by NetID, for
+	// the students who have any
+	Repeats map[string]int `json:"repeats,omitempty"`
+
+	// Satisfaction describes how many of every student's preferences were honored, if any were given
+	Satisfaction []Satisfaction `json:"satisfaction,omitempty"`
+
+	// Skills describe the skills of every group, if students have skill scores
+	Skills []SkillProfile `json:"skills,omitempty"`
+
+	// Explanations describe how every student ended up in their group for every project, if they were asked for
+	Explanations []Explanation `json:"explanations,omitempty"`
+}
+
+// SkillProfile describes the skills of the members of one group
+type SkillProfile struct {
+	// Project is the name of the project the group is for
+	Project string `json:"project"`
+
+	// Group is the number of the group in the project, counting from one
+	Group int `json:"group"`
+
+	// Mean is the average score of the members for every skill
+	Mean map[string]float64 `json:"mean"`
+
+	// Spread is the standard deviation of the members' scores for every skill
+	Spread map[string]float64 `json:"spread"`
+}
+
+// Satisfaction describes how many of a student's preferences were honored
+type Satisfaction struct {
+	// Student is the NetID of the student who made the requests
+	Student string `json:"student"`
+
+	// Honored is the number of requests that were honored
+	Honored int `json:"honored"`
+
+	// Requested is the number of requests made for the projects that were grouped
+	Requested int `json:"requested"`
+}
+
+// WorkerSummary describes the outcome of one of many independent generations made in parallel
+type WorkerSummary struct {
+	// Seed is the seed of the random source used by the worker
+	Seed int64 `json:"seed"`
+
+	// Repairings is the number of repairings in the worker's grouping, or -1 if it found none
+	Repairings int `json:"repairings"`
+
+	// Score is the value of the objective for the worker's grouping
+	Score float64 `json:"score"`
+
+	// Attempts is the number of attempts the worker made
+	Attempts int `json:"attempts"`
+
+	// Partial is set when the worker was stopped before it finished optimizing its grouping
+	Partial bool `json:"partial,omitempty"`
+
+	// Error describes why the worker failed or was stopped, if it was
+	Error string `json:"error,omitempty"`
 }
 
 // ProjectGrouping is a collection of groups that contain all members of a class
@@ -18,10 +113,177 @@ type ProjectGrouping struct {
 type Group struct {
 	// Members are the student members of a group
 	Members []Student `json:"students"`
+
+	// Section is the section all members of the group belong to, if the class has sections
+	Section string `json:"section,omitempty"`
+
+	// Meetings are the weekly windows every member of the group is free, like "Mon 09:00-11:00",
+	// if students' availability was given
+	Meetings []string `json:"meetings,omitempty"`
 }
 
 // Student represents a student in the class
 type Student struct {
 	FullName string `json:"name"`
 	NetID    string `json:"netID"`
+
+	// Attributes describe the student, like their major or year, keyed by
+	// the name of the attribute
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// Section is the section of the class, like a lab section, that the
+	// student belongs to. Students are only grouped with others in their section.
+	Section string `json:"section,omitempty"`
+
+	// Role is the role the student has in their group, if the project has roles
+	Role string `json:"role,omitempty"`
+
+	// Skills are numeric scores for the student's skills, like a prior grade or a
+	// self-assessment, keyed by the name of the skill
+	Skills map[string]float64 `json:"skills,omitempty"`
+}
+
+// Preference records who a student would like to work with, and who they would like not
+// to work with. Unlike constraints, preferences may be broken.
+type Preference struct {
+	// Student is the NetID of the student with the preference
+	Student string `json:"student"`
+
+	// Preferred are the NetIDs of students they would like to share a group with
+	Preferred []string `json:"preferred,omitempty"`
+
+	// Avoided are the NetIDs of students they would like not to share a group with
+	Avoided []string `json:"avoided,omitempty"`
+
+	// Projects are the names of the projects the preference applies to. If none are
+	// given, the preference applies to all projects.
+	Projects []string `json:"projects,omitempty"`
+}
+
+// ProjectSize configures the sizes of the groups for one project. Either an optimal
+// size or a range of sizes may be given; if neither is, the default optimal size is used.
+type ProjectSize struct {
+	// Name is the name of the project
+	Name string `json:"name"`
+
+	// OptimalSize is the optimal number of members for groups. When the class can't be
+	// evenly divided into groups, some are made smaller or larger than it.
+	OptimalSize int `json:"optimalSize,omitempty"`
+
+	// MinSize and MaxSize bound the number of members of every group
+	MinSize int `json:"minSize,omitempty"`
+	MaxSize int `json:"maxSize,omitempty"`
+}
+
+// Days are the days of the week, in order, that time slots may fall on
+var Days = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// TimeSlot is an hour of the week, starting at the given hour of the day
+type TimeSlot struct {
+	// Day is one of the Days
+	Day string `json:"day"`
+
+	// Hour is the hour of the day the slot starts at, from 0 to 23
+	Hour int `json:"hour"`
+}
+
+// Availability records the hours of the week a student is free to meet with their group
+type Availability struct {
+	// Student is the NetID of the student
+	Student string `json:"student"`
+
+	// Free are the slots the student is free in
+	Free []TimeSlot `json:"free,omitempty"`
+}
+
+// ProjectRoles configures the roles members take on in the groups for one project
+type ProjectRoles struct {
+	// Name is the name of the project
+	Name string `json:"name"`
+
+	// Roles are the roles every group fills, in order. When a group has more members
+	// than roles, roles are shared starting from the first; when it has fewer, the
+	// last roles go unfilled.
+	Roles []string `json:"roles"`
+}
+
+const (
+	// MustBeTogether is the kind of constraint that requires two students to share a group
+	MustBeTogether = "together"
+
+	// MustBeApart is the kind of constraint that forbids two students from sharing a group
+	MustBeApart = "apart"
+)
+
+// Constraint is a hard rule about whether two students may share a group
+type Constraint struct {
+	// Kind is either MustBeTogether or MustBeApart
+	Kind string `json:"kind"`
+
+	// Student and Partner are the NetIDs of the students the rule is about
+	Student string `json:"student"`
+	Partner string `json:"partner"`
+
+	// Projects are the names of the projects the rule applies to. If none are given,
+	// the rule applies to all projects.
+	Projects []string `json:"projects,omitempty"`
+}
+
+// Stats describes how the students on a roster collaborate across a set of project groupings
+type Stats struct {
+	// Students are the NetIDs of the students on the roster, in the order of the rows and columns of Collaborations
+	Students []string `json:"students"`
+
+	// Collaborations counts the groups every pair of students shared, as a matrix with a row and a column for
+	// every student
+	Collaborations [][]int `json:"collaborations"`
+
+	// Repairings is the number of times students were grouped with someone they had already collaborated with
+	Repairings int `json:"repairings"`
+
+	// Repeats are the number of repeated collaborations every student has, by NetID, for the students who have any
+	Repeats map[string]int `json:"repeats,omitempty"`
+
+	// Coverage describes how many of their classmates every student has collaborated with, in the order of Students
+	Coverage []PartnerCoverage `json:"coverage"`
+
+	// GroupSizes count the groups of every size for every project, in the order the projects were given
+	GroupSizes []GroupSizes `json:"groupSizes"`
+}
+
+// PartnerCoverage describes how many of a student's classmates they have collaborated with
+type PartnerCoverage struct {
+	// Student is the NetID of the student
+	Student string `json:"student"`
+
+	// Partners is the number of different classmates the student has collaborated with
+	Partners int `json:"partners"`
+
+	// Classmates is the number of other students in the student's section, who they could collaborate with
+	Classmates int `json:"classmates"`
+}
+
+// GroupSizes counts the groups of every size for a project
+type GroupSizes struct {
+	// Project is the name of the project
+	Project string `json:"project"`
+
+	// Counts are the number of groups with every number of members
+	Counts map[int]int `json:"counts"`
+}
+
+// Explanation describes how a student ended up in their group for a project
+type Explanation struct {
+	// Student is the NetID of the student
+	Student string `json:"student"`
+
+	// Project is the name of the project
+	Project string `json:"project"`
+
+	// Group is the number of the student's group in the project, counting from one
+	Group int `json:"group"`
+
+	// Narrative describes what happened to the student while the project was grouped, in order, followed by
+	// who they ended up with and where they had worked with any of them before
+	Narrative []string `json:"narrative"`
 }
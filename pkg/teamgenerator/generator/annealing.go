@@ -0,0 +1,287 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// separationPenalty is added to the objective for every pair of students sharing a group that should have been kept
+// apart, so that the search moves away from groupings that break the rules
+const separationPenalty = 1000
+
+// Schedule is the cooling schedule for a simulated annealing search
+type Schedule struct {
+	// InitialTemperature is the temperature at which every run of the search starts
+	InitialTemperature float64
+
+	// CoolingRate is the factor by which the temperature is multiplied to cool it
+	CoolingRate float64
+
+	// MinimumTemperature is the temperature at which a run of the search is finished
+	MinimumTemperature float64
+
+	// SwapsPerTemperature is the number of swaps that are tried before the temperature is cooled
+	SwapsPerTemperature int
+}
+
+// DefaultSchedule returns a cooling schedule that works well for classes of around sixty students
+func DefaultSchedule() Schedule {
+	return Schedule{
+		InitialTemperature:  2,
+		CoolingRate:         0.95,
+		MinimumTemperature:  0.01,
+		SwapsPerTemperature: 200,
+	}
+}
+
+// NewAnnealingClassGrouping returns a ClassGrouping that starts from a random assignment of students to groups and
+// improves it by swapping pairs of students between groups, using simulated annealing with the given schedule
+func NewAnnealingClassGrouping(optimalGroupSize int, preferSmallerGroups bool, schedule Schedule, opts ...Option) ClassGrouping {
+	return &annealingClassGrouping{
+		classGrouping: classGrouping{optimalGroupSize: optimalGroupSize, preferSmallerGroups: preferSmallerGroups, options: newOptions(opts)},
+		schedule:      schedule,
+	}
+}
+
+type annealingClassGrouping struct {
+	classGrouping
+	schedule Schedule
+}
+
+// Generate generates a class grouping from a roster
+func (g *annealingClassGrouping) Generate(students []api.Student, groupingNames []string) api.ClassGrouping {
+	return g.GenerateWithPriors(students, nil, groupingNames)
+}
+
+// GenerateWithPriors generates a class grouping from a roster, taking into account prior groupings
+func (g *annealingClassGrouping) GenerateWithPriors(students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string) api.ClassGrouping {
+	grouping, _ := g.GenerateWithPriorsContext(context.Background(), students, priorGroupings, groupingNames, Budget{})
+	return grouping
+}
+
+// GenerateContext generates a class grouping from a roster, within the given budget
+func (g *annealingClassGrouping) GenerateContext(ctx context.Context, students []api.Student, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	return g.GenerateWithPriorsContext(ctx, students, nil, groupingNames, budget)
+}
+
+// GenerateWithPriorsContext generates a class grouping from a roster, taking into account prior groupings,
+// within the given budget. Every attempt is a full run of the cooling schedule from a new random assignment;
+// without an attempt budget only one run is made.
+func (g *annealingClassGrouping) GenerateWithPriorsContext(ctx context.Context, students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	if err := g.checkConstraints(students, groupingNames); err != nil {
+		return api.ClassGrouping{}, err
+	}
+
+	ctx, cancel := budget.apply(ctx)
+	defer cancel()
+
+	random, seed := g.newRandom()
+	best := newBestGrouping(seed, &g.options)
+
+	runs := 1
+	if budget.Attempts > 0 {
+		runs = budget.Attempts
+	}
+
+	// lastErr is why the last attempt could not be started, if it couldn't
+	var lastErr error
+
+	for attempts := 0; attempts < runs; attempts++ {
+		if best.found && best.score <= 0 {
+			// nothing can be better than a perfect grouping
+			return best.result(attempts, nil)
+		}
+
+		if err := stopSearch(ctx, budget, attempts, best); err != nil {
+			return best.result(attempts, err)
+		}
+
+		_, projects, err := g.newProjects(students, priorGroupings, groupingNames, random)
+		if err != nil {
+			// we could not place students that must be together in this attempt, but we may in the next
+			lastErr = err
+			continue
+		}
+		for _, project := range projects {
+			if g.balanceSkills {
+				fillSnakeDraft(project, g.balancedSkills, random)
+			} else {
+				fillRandomly(project, random)
+			}
+		}
+
+		if err := g.anneal(ctx, projects, random, best); err != nil {
+			return best.result(attempts+1, stopSearch(ctx, budget, attempts+1, best))
+		}
+	}
+
+	if !best.found {
+		if lastErr == nil {
+			return api.ClassGrouping{}, g.apartConflict(groupingNames, fmt.Sprintf("%d attempts found no grouping that follows", runs))
+		}
+		return best.result(runs, &PartialGroupingError{Reason: fmt.Sprintf("search could not start an attempt: %v", lastErr), Attempts: runs, Repairings: -1})
+	}
+	return best.result(runs, nil)
+}
+
+// anneal runs the cooling schedule once over the fully grouped projects, recording any better grouping it comes
+// across. Groupings that keep students together who must be kept apart are allowed along the way, but are heavily
+// penalized and are never recorded. An error is returned if the context is done before the run is finished.
+func (g *annealingClassGrouping) anneal(ctx context.Context, projects []*Project, random *rand.Rand, best *bestGrouping) error {
+	swappable := swappableGroups(projects)
+	score, separations := g.score(projects), countSeparations(projects)
+	if separations == 0 {
+		best.consider(projects, score)
+	}
+
+	for temperature := g.schedule.InitialTemperature; temperature > g.schedule.MinimumTemperature; temperature *= g.schedule.CoolingRate {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		for i := 0; i < g.schedule.SwapsPerTemperature; i++ {
+			swap, ok := randomSwap(swappable, random)
+			if !ok {
+				// no two students can be swapped, so there is nothing left to search
+				return nil
+			}
+
+			swap.apply()
+			newScore, newSeparations := g.score(projects), countSeparations(projects)
+			delta := newScore - score + separationPenalty*float64(newSeparations-separations)
+			if delta <= 0 || random.Float64() < math.Exp(-delta/temperature) {
+				score, separations = newScore, newSeparations
+				if separations == 0 {
+					best.consider(projects, score)
+					if score <= 0 {
+						return nil
+					}
+				}
+			} else {
+				swap.apply()
+			}
+		}
+	}
+
+	return nil
+}
+
+// countSeparations counts the pairs of students sharing a group that should have been kept apart in all projects
+func countSeparations(projects []*Project) int {
+	separations := 0
+	for _, project := range projects {
+		separations += project.separations()
+	}
+	return separations
+}
+
+// fillRandomly assigns all ungrouped students in the project to groups for their section at random, keeping
+// students apart where it can
+func fillRandomly(project *Project, random *rand.Rand) {
+	ungrouped := append([]*Student{}, project.UngroupedStudents...)
+	random.Shuffle(len(ungrouped), func(i, j int) {
+		ungrouped[i], ungrouped[j] = ungrouped[j], ungrouped[i]
+	})
+
+	for _, student := range ungrouped {
+		var chosen *Group
+		for _, group := range project.Groups {
+			if group.IsFull() || group.Section != student.Section {
+				continue
+			}
+			if chosen == nil || project.CanJoin(group, student) && !project.CanJoin(chosen, student) {
+				chosen = group
+			}
+		}
+		if chosen == nil {
+			return
+		}
+		chosen.AddMember(student)
+		project.MarkStudentGrouped(student)
+	}
+}
+
+// swap exchanges two students between groups in the same project
+type swap struct {
+	group, otherGroup     *Group
+	student, otherStudent *Student
+}
+
+// apply exchanges the students; applying a swap a second time undoes it
+func (s *swap) apply() {
+	s.group.RemoveMember(s.student)
+	s.otherGroup.RemoveMember(s.otherStudent)
+	s.group.AddMember(s.otherStudent)
+	s.otherGroup.AddMember(s.student)
+	s.student, s.otherStudent = s.otherStudent, s.student
+}
+
+// swappableGroup is a group with students that may be swapped out of it
+type swappableGroup struct {
+	project *Project
+	group   *Group
+}
+
+// unlockedMembers returns the members of the group that may be swapped out of it
+func (s swappableGroup) unlockedMembers() []*Student {
+	var members []*Student
+	for _, member := range s.group.members {
+		if !s.project.IsLocked(member) {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// swappableGroups finds, for every section of every project with at least two of them, the groups that have
+// students which may be swapped out. Swaps only ever exchange unlocked students within a section, so these groups
+// do not change as swaps are made.
+func swappableGroups(projects []*Project) [][]swappableGroup {
+	var swappable [][]swappableGroup
+	for _, project := range projects {
+		var sections []string
+		groupsInSection := map[string][]swappableGroup{}
+		for _, group := range project.Groups {
+			candidate := swappableGroup{project: project, group: group}
+			if len(candidate.unlockedMembers()) == 0 {
+				continue
+			}
+			if _, seen := groupsInSection[group.Section]; !seen {
+				sections = append(sections, group.Section)
+			}
+			groupsInSection[group.Section] = append(groupsInSection[group.Section], candidate)
+		}
+		for _, section := range sections {
+			if groups := groupsInSection[section]; len(groups) > 1 {
+				swappable = append(swappable, groups)
+			}
+		}
+	}
+	return swappable
+}
+
+// randomSwap chooses two unlocked students in different groups of a random section of a project to swap
+func randomSwap(swappable [][]swappableGroup, random *rand.Rand) (*swap, bool) {
+	if len(swappable) == 0 {
+		return nil, false
+	}
+
+	groups := swappable[random.Intn(len(swappable))]
+	first := random.Intn(len(groups))
+	second := random.Intn(len(groups) - 1)
+	if second >= first {
+		second++
+	}
+	members, otherMembers := groups[first].unlockedMembers(), groups[second].unlockedMembers()
+
+	return &swap{
+		group:        groups[first].group,
+		otherGroup:   groups[second].group,
+		student:      members[random.Intn(len(members))],
+		otherStudent: otherMembers[random.Intn(len(otherMembers))],
+	}, true
+}
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// checkEveryStudentGroupedOnce ensures that every student on the roster is in exactly one group for every project
+func checkEveryStudentGroupedOnce(t *testing.T, name string, roster []api.Student, grouping api.ClassGrouping) {
+	for _, project := range grouping.Projects {
+		seen := map[string]int{}
+		for _, group := range project.Groups {
+			for _, member := range group.Members {
+				seen[member.NetID]++
+			}
+		}
+
+		for _, student := range roster {
+			if seen[student.NetID] != 1 {
+				t.Errorf("%s: expected %s to be grouped once for %s, was grouped %d times", name, student.NetID, project.Name, seen[student.NetID])
+			}
+		}
+		if len(seen) != len(roster) {
+			t.Errorf("%s: expected %d students to be grouped for %s, got %d", name, len(roster), project.Name, len(seen))
+		}
+	}
+}
+
+func TestAnnealingFindsRepeatFreeGrouping(t *testing.T) {
+	roster := testRoster(12)
+	projects := []string{"first", "second", "third"}
+	schedule := Schedule{InitialTemperature: 1, CoolingRate: 0.9, MinimumTemperature: 0.01, SwapsPerTemperature: 100}
+	generator := NewAnnealingClassGrouping(3, false, schedule, WithSeed(42))
+
+	grouping, err := generator.GenerateContext(context.Background(), roster, projects, Budget{Attempts: 5})
+	if err != nil {
+		t.Fatalf("failed to generate grouping: %v", err)
+	}
+
+	if len(grouping.Projects) != len(projects) {
+		t.Fatalf("expected %d projects, got %d", len(projects), len(grouping.Projects))
+	}
+	checkEveryStudentGroupedOnce(t, "annealing", roster, grouping)
+
+	if grouping.Summary.Repairings != 0 {
+		t.Errorf("expected annealing to find a grouping without repairings, got %d", grouping.Summary.Repairings)
+	}
+}
+
+func TestAnnealingReportsWhenNothingIsFound(t *testing.T) {
+	// students kept apart in a ring of five can't be split between two groups, although any two of them can
+	constraints := apartRing(5)
+	generator := NewAnnealingClassGrouping(5, false, DefaultSchedule(), WithSeed(1), WithConstraints(constraints))
+
+	for _, budget := range []Budget{{}, {Attempts: 3}} {
+		_, err := generator.GenerateContext(context.Background(), testRoster(10), []string{"design"}, budget)
+		conflict, ok := err.(*ConstraintConflictError)
+		if !ok {
+			t.Errorf("%d attempts: expected a constraint conflict, got %v", budget.Attempts, err)
+			continue
+		}
+		expected := []string{fmt.Sprintf("for design, %d attempts found no grouping that follows rules %s", max(1, budget.Attempts), describeConstraints(constraints))}
+		if !reflect.DeepEqual(conflict.Conflicts, expected) {
+			t.Errorf("%d attempts: correct conflicts not found:\n\twanted:\n\t%v\n\tgot:\n\t%v", budget.Attempts, expected, conflict.Conflicts)
+		}
+	}
+}
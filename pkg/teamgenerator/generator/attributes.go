@@ -0,0 +1,87 @@
+package generator
+
+import "math"
+
+// NewAttributeSpreadScorer returns a scorer that measures how unevenly the values of an attribute,
+// like a student's major or year, are spread across the groups of every project
+func NewAttributeSpreadScorer(attribute string) Scorer {
+	return &attributeSpreadScorer{attribute: attribute}
+}
+
+type attributeSpreadScorer struct {
+	attribute string
+}
+
+// Score counts how many students every group is away from its fair share of each value of the
+// attribute. A group's fair share of a value is the fraction of the class that holds that value,
+// scaled to the size of the group and rounded either up or down. Students without a value for
+// the attribute are not counted.
+func (s *attributeSpreadScorer) Score(projects []*Project) float64 {
+	var score float64
+	for _, project := range projects {
+		totals := map[string]int{}
+		numStudents := len(project.UngroupedStudents)
+		for _, student := range project.UngroupedStudents {
+			if value, ok := student.Attributes[s.attribute]; ok {
+				totals[value]++
+			}
+		}
+		for _, group := range project.Groups {
+			numStudents += len(group.Members())
+			for value, count := range attributeCounts(group, s.attribute) {
+				totals[value] += count
+			}
+		}
+
+		for _, group := range project.Groups {
+			counts := attributeCounts(group, s.attribute)
+			for value, total := range totals {
+				share := float64(total*len(group.Members())) / float64(numStudents)
+				lowest, highest := math.Floor(share), math.Ceil(share)
+				count := float64(counts[value])
+				score += math.Max(0, math.Max(lowest-count, count-highest))
+			}
+		}
+	}
+	return score
+}
+
+// NewIsolationScorer returns a scorer that counts how many times a student is the only member of
+// their group to hold a value of an attribute. If a value is given, only students holding that value
+// are considered; otherwise, every value of the attribute is.
+func NewIsolationScorer(attribute, value string) Scorer {
+	return &isolationScorer{attribute: attribute, value: value}
+}
+
+type isolationScorer struct {
+	attribute, value string
+}
+
+// Score counts the isolated students in every project. A student alone in a group is not isolated.
+func (s *isolationScorer) Score(projects []*Project) float64 {
+	isolated := 0
+	for _, project := range projects {
+		for _, group := range project.Groups {
+			if len(group.Members()) < 2 {
+				continue
+			}
+			for value, count := range attributeCounts(group, s.attribute) {
+				if count == 1 && (len(s.value) == 0 || value == s.value) {
+					isolated++
+				}
+			}
+		}
+	}
+	return float64(isolated)
+}
+
+// attributeCounts counts the members of the group holding every value of the attribute
+func attributeCounts(group *Group, attribute string) map[string]int {
+	counts := map[string]int{}
+	for _, member := range group.Members() {
+		if value, ok := member.Attributes[attribute]; ok {
+			counts[value]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// attributeRoster creates a roster where every student holds the given value of the attribute
+func attributeRoster(attribute string, values ...string) []*Student {
+	var roster []*Student
+	for i, value := range values {
+		student := api.Student{FullName: fmt.Sprintf("Student %d", i), NetID: fmt.Sprintf("s%d@duke.edu", i)}
+		if len(value) > 0 {
+			student.Attributes = map[string]string{attribute: value}
+		}
+		roster = append(roster, NewStudent(student))
+	}
+	indexRoster(roster)
+	return roster
+}
+
+func TestAttributeSpreadScorer(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		values        []string
+		groups        [][]int
+		expectedScore float64
+	}{
+		{
+			name:          "evenly spread",
+			values:        []string{"ME", "ME", "BME", "BME"},
+			groups:        [][]int{{0, 2}, {1, 3}},
+			expectedScore: 0,
+		},
+		{
+			name:          "clustered",
+			values:        []string{"ME", "ME", "BME", "BME"},
+			groups:        [][]int{{0, 1}, {2, 3}},
+			expectedScore: 4,
+		},
+		{
+			name:          "fair share may be rounded either way",
+			values:        []string{"ME", "ME", "ME", "BME", "BME", "BME"},
+			groups:        [][]int{{0, 1, 3}, {2, 4, 5}},
+			expectedScore: 0,
+		},
+		{
+			name:          "students without the attribute are not counted",
+			values:        []string{"ME", "", "ME", ""},
+			groups:        [][]int{{0, 1}, {2, 3}},
+			expectedScore: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		roster := attributeRoster("major", testCase.values...)
+		projects := []*Project{testProject(roster, testCase.groups...)}
+		if actual, expected := NewAttributeSpreadScorer("major").Score(projects), testCase.expectedScore; actual != expected {
+			t.Errorf("%s: did not score spread correctly, expected %g, got %g", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestIsolationScorer(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		value         string
+		values        []string
+		groups        [][]int
+		expectedScore float64
+	}{
+		{
+			name:          "no isolation",
+			value:         "F",
+			values:        []string{"F", "F", "M", "M", "M", "M"},
+			groups:        [][]int{{0, 1, 2}, {3, 4, 5}},
+			expectedScore: 0,
+		},
+		{
+			name:          "isolated value",
+			value:         "F",
+			values:        []string{"F", "F", "M", "M", "M", "M"},
+			groups:        [][]int{{0, 2, 3}, {1, 4, 5}},
+			expectedScore: 2,
+		},
+		{
+			name:          "other values may be isolated",
+			value:         "F",
+			values:        []string{"F", "F", "M", "F", "F", "M"},
+			groups:        [][]int{{0, 1, 2}, {3, 4, 5}},
+			expectedScore: 0,
+		},
+		{
+			name:          "every value counts without a value",
+			values:        []string{"F", "F", "M", "F", "F", "M"},
+			groups:        [][]int{{0, 1, 2}, {3, 4, 5}},
+			expectedScore: 2,
+		},
+		{
+			name:          "students alone are not isolated",
+			value:         "F",
+			values:        []string{"F", "M", "M"},
+			groups:        [][]int{{0}, {1, 2}},
+			expectedScore: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		roster := attributeRoster("gender", testCase.values...)
+		projects := []*Project{testProject(roster, testCase.groups...)}
+		if actual, expected := NewIsolationScorer("gender", testCase.value).Score(projects), testCase.expectedScore; actual != expected {
+			t.Errorf("%s: did not count isolated students correctly, expected %g, got %g", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestAdditionalScorersKeepDefaultObjective(t *testing.T) {
+	roster := attributeRoster("gender", "F", "M", "M", "M")
+	projects := []*Project{testProject(roster, []int{0, 1}, []int{2, 3}), testProject(roster, []int{0, 1}, []int{2, 3})}
+
+	o := newOptions([]Option{WithNoIsolation("gender", "F", 10)})
+	if actual, expected := o.score(projects), 2.0+2*10; actual != expected {
+		t.Errorf("objective did not add isolation to repairings, expected %g, got %g", expected, actual)
+	}
+}
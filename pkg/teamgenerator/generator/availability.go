@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewAvailabilityScorer returns a scorer that counts the hours every group is short of the number of hours
+// in the week when all of its members are free to meet
+func NewAvailabilityScorer(minHours int) Scorer {
+	return &availabilityScorer{minHours: minHours}
+}
+
+type availabilityScorer struct {
+	minHours int
+}
+
+// Score counts the hours groups are short of the minimum, ignoring groups where nobody gave their availability
+func (s *availabilityScorer) Score(projects []*Project) float64 {
+	shortfall := 0
+	for _, project := range projects {
+		for _, group := range project.Groups {
+			if shared, known := sharedHours(group.members); known {
+				shortfall += max(0, s.minHours-len(shared))
+			}
+		}
+	}
+	return float64(shortfall)
+}
+
+// sharedHours finds the hours in the week when every member who gave their availability is free, in order.
+// It also determines if any member gave their availability at all.
+func sharedHours(members []*Student) ([]api.TimeSlot, bool) {
+	var shared []api.TimeSlot
+	known := false
+	for _, member := range members {
+		if member.free == nil {
+			continue
+		}
+		if !known {
+			for slot := range member.free {
+				shared = append(shared, slot)
+			}
+			known = true
+			continue
+		}
+		shared = slices.DeleteFunc(shared, func(slot api.TimeSlot) bool {
+			return !member.free[slot]
+		})
+	}
+
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].Day != shared[j].Day {
+			return slices.Index(api.Days, shared[i].Day) < slices.Index(api.Days, shared[j].Day)
+		}
+		return shared[i].Hour < shared[j].Hour
+	})
+	return shared, known
+}
+
+// meetingWindows joins the hours, in order, into windows of consecutive hours like "Mon 09:00-11:00"
+func meetingWindows(hours []api.TimeSlot) []string {
+	var windows []string
+	for start := 0; start < len(hours); {
+		end := start + 1
+		for end < len(hours) && hours[end].Day == hours[start].Day && hours[end].Hour == hours[end-1].Hour+1 {
+			end++
+		}
+		windows = append(windows, fmt.Sprintf("%s %02d:00-%02d:00", hours[start].Day, hours[start].Hour, hours[end-1].Hour+1))
+		start = end
+	}
+	return windows
+}
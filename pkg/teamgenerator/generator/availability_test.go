@@ -0,0 +1,114 @@
+package generator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestSharedHours(t *testing.T) {
+	var testCases = []struct {
+		name             string
+		free             [][]api.TimeSlot
+		expectedMeetings []string
+		expectedKnown    bool
+		expectedShort    float64
+	}{
+		{
+			name:          "nobody gave availability",
+			free:          [][]api.TimeSlot{nil, nil},
+			expectedKnown: false,
+			expectedShort: 0,
+		},
+		{
+			name:             "consecutive hours are joined",
+			free:             [][]api.TimeSlot{{{Day: "Tue", Hour: 9}, {Day: "Mon", Hour: 10}, {Day: "Mon", Hour: 9}, {Day: "Mon", Hour: 12}}, {{Day: "Mon", Hour: 9}, {Day: "Mon", Hour: 10}, {Day: "Mon", Hour: 12}, {Day: "Tue", Hour: 9}}},
+			expectedMeetings: []string{"Mon 09:00-11:00", "Mon 12:00-13:00", "Tue 09:00-10:00"},
+			expectedKnown:    true,
+			expectedShort:    0,
+		},
+		{
+			name:             "students without availability are free whenever the others are",
+			free:             [][]api.TimeSlot{{{Day: "Wed", Hour: 15}}, nil},
+			expectedMeetings: []string{"Wed 15:00-16:00"},
+			expectedKnown:    true,
+			expectedShort:    1,
+		},
+		{
+			name:          "no hours in common",
+			free:          [][]api.TimeSlot{{{Day: "Mon", Hour: 9}}, {{Day: "Mon", Hour: 10}}},
+			expectedKnown: true,
+			expectedShort: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var members []*Student
+		for i, free := range testCase.free {
+			student := NewStudent(testRoster(len(testCase.free))[i])
+			if free != nil {
+				student.free = map[api.TimeSlot]bool{}
+				for _, slot := range free {
+					student.free[slot] = true
+				}
+			}
+			members = append(members, student)
+		}
+		indexRoster(members)
+
+		shared, known := sharedHours(members)
+		if known != testCase.expectedKnown {
+			t.Errorf("%s: expected knowing availability to be %v, got %v", testCase.name, testCase.expectedKnown, known)
+		}
+		if actual := meetingWindows(shared); !reflect.DeepEqual(actual, testCase.expectedMeetings) {
+			t.Errorf("%s: expected meetings %v, got %v", testCase.name, testCase.expectedMeetings, actual)
+		}
+		if actual := NewAvailabilityScorer(2).Score([]*Project{testProject(members, []int{0, 1})}); actual != testCase.expectedShort {
+			t.Errorf("%s: expected to be %g hours short, got %g", testCase.name, testCase.expectedShort, actual)
+		}
+	}
+}
+
+func TestGenerateFindsSharedHours(t *testing.T) {
+	// students are free in the mornings or in the afternoons, so groups of three can only meet
+	// if all of their members share the same half of the day
+	roster := testRoster(6)
+	var availability []api.Availability
+	for i, student := range roster {
+		hour := 9
+		if i%2 == 1 {
+			hour = 14
+		}
+		availability = append(availability, api.Availability{Student: student.NetID, Free: []api.TimeSlot{{Day: "Mon", Hour: hour}, {Day: "Mon", Hour: hour + 1}}})
+	}
+
+	var testCases = []struct {
+		name      string
+		generator ClassGrouping
+	}{
+		{
+			name:      "reshuffle",
+			generator: NewClassGrouping(3, false, WithSeed(1), WithAvailability(availability, 2, 10)),
+		},
+		{
+			name:      "anneal",
+			generator: NewAnnealingClassGrouping(3, false, DefaultSchedule(), WithSeed(1), WithAvailability(availability, 2, 10)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		grouping, err := testCase.generator.GenerateContext(context.Background(), roster, []string{"design"}, Budget{Attempts: 1})
+		if !foundGrouping(grouping, err) {
+			t.Errorf("%s: expected a grouping, got error %v", testCase.name, err)
+			continue
+		}
+
+		for i, group := range grouping.Projects[0].Groups {
+			if len(group.Meetings) != 1 {
+				t.Errorf("%s: expected group %d to have one window to meet in, got %v", testCase.name, i, group.Meetings)
+			}
+		}
+	}
+}
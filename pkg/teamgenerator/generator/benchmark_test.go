@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/parser"
+)
+
+// benchmarkProjects is the number of projects grouped in every benchmark
+const benchmarkProjects = 15
+
+// benchmarkRoster benchmarks a strategy on the example roster, reporting how many repairings it made
+func benchmarkRoster(b *testing.B, newClassGrouping func(seed int64) ClassGrouping) {
+	roster, err := parser.NewCSVRoster().Parse("../roster.csv")
+	if err != nil {
+		b.Fatalf("failed to parse roster: %v", err)
+	}
+
+	var projects []string
+	for i := 0; i < benchmarkProjects; i++ {
+		projects = append(projects, fmt.Sprintf("project %d", i))
+	}
+
+	repairings := 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		grouping, err := newClassGrouping(int64(i)).GenerateContext(context.Background(), roster, projects, Budget{})
+		if err != nil {
+			b.Fatalf("failed to generate grouping: %v", err)
+		}
+		repairings += grouping.Summary.Repairings
+	}
+	b.ReportMetric(float64(repairings)/float64(b.N), "repairings/op")
+}
+
+func BenchmarkReshuffleRoster(b *testing.B) {
+	benchmarkRoster(b, func(seed int64) ClassGrouping {
+		return NewClassGrouping(3, false, WithSeed(seed))
+	})
+}
+
+func BenchmarkAnnealingRoster(b *testing.B) {
+	benchmarkRoster(b, func(seed int64) ClassGrouping {
+		return NewAnnealingClassGrouping(3, false, DefaultSchedule(), WithSeed(seed))
+	})
+}
+
+// benchmarkCohortProjects is the number of projects grouped in every cohort benchmark
+const benchmarkCohortProjects = 5
+
+// benchmarkCohort benchmarks one attempt of the reshuffling strategy on a class of the given size, so that the cost
+// of tracking collaborations can be compared as classes grow. An attempt that runs out of reshuffles has done as much
+// work as one that succeeds, so it doesn't fail the benchmark.
+func benchmarkCohort(b *testing.B, numStudents int) {
+	roster := testRoster(numStudents)
+
+	var projects []string
+	for i := 0; i < benchmarkCohortProjects; i++ {
+		projects = append(projects, fmt.Sprintf("project %d", i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generator := NewClassGrouping(4, false, WithSeed(int64(i)))
+		_, err := generator.GenerateContext(context.Background(), roster, projects, Budget{Attempts: 1})
+		if _, partial := err.(*PartialGroupingError); err != nil && !partial {
+			b.Fatalf("failed to generate grouping: %v", err)
+		}
+	}
+}
+
+func BenchmarkCohort60(b *testing.B) {
+	benchmarkCohort(b, 60)
+}
+
+func BenchmarkCohort400(b *testing.B) {
+	benchmarkCohort(b, 400)
+}
+
+func BenchmarkCohort2000(b *testing.B) {
+	benchmarkCohort(b, 2000)
+}
@@ -0,0 +1,24 @@
+package generator
+
+// bitset is a set of small non-negative integers, such as the indices of students in a class
+type bitset []uint64
+
+// bitsetWords is the number of words a bitset needs to hold every integer below the size
+func bitsetWords(size int) int {
+	return (size + 63) / 64
+}
+
+// has determines if the integer is in the set
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+// set adds the integer to the set
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+// clear removes the integer from the set
+func (b bitset) clear(i int) {
+	b[i/64] &^= 1 << (i % 64)
+}
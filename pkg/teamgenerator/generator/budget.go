@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Budget limits how long a generation may search for a grouping. The zero value places no limits on the search.
+type Budget struct {
+	// Timeout is the wall-clock time the search may take, if non-zero
+	Timeout time.Duration
+
+	// Attempts is the number of attempts at a full grouping the search may make, if non-zero
+	Attempts int
+}
+
+// apply derives a context that is done when the wall-clock budget is spent
+func (b Budget) apply(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.Timeout > 0 {
+		return context.WithTimeout(ctx, b.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// exhausted determines if the given number of attempts have spent the attempt budget
+func (b Budget) exhausted(attempts int) bool {
+	return b.Attempts > 0 && attempts >= b.Attempts
+}
+
+// PartialGroupingError is returned alongside the best grouping found so far when the search for a
+// grouping is stopped before it has finished optimizing
+type PartialGroupingError struct {
+	// Reason describes why the search was stopped
+	Reason string
+
+	// Attempts is the number of attempts the search made before it was stopped
+	Attempts int
+
+	// Repairings is the number of repairings in the best grouping found, or -1 if no
+	// attempt managed to group every student
+	Repairings int
+
+	// Score is the value of the objective for the best grouping found
+	Score float64
+
+	// cause is the context error that stopped the search, if any
+	cause error
+}
+
+func (e *PartialGroupingError) Error() string {
+	if e.Repairings < 0 {
+		return fmt.Sprintf("%s after %d attempts without finding a grouping", e.Reason, e.Attempts)
+	}
+	return fmt.Sprintf("%s after %d attempts, best grouping found has %d repairings and a score of %g", e.Reason, e.Attempts, e.Repairings, e.Score)
+}
+
+// Unwrap exposes the context error that stopped the search, if any
+func (e *PartialGroupingError) Unwrap() error {
+	return e.cause
+}
+
+// Found determines if any grouping was found before the search was stopped
+func (e *PartialGroupingError) Found() bool {
+	return e.Repairings >= 0
+}
+
+// stopSearch determines if the search needs to stop before making another attempt and why
+func stopSearch(ctx context.Context, budget Budget, attempts int, best *bestGrouping) error {
+	if err := ctx.Err(); err != nil {
+		reason := "search was cancelled"
+		if err == context.DeadlineExceeded {
+			reason = "search ran out of time"
+		}
+		return &PartialGroupingError{Reason: reason, Attempts: attempts, Repairings: best.repairings(), Score: best.score, cause: err}
+	}
+
+	if budget.exhausted(attempts) {
+		return &PartialGroupingError{Reason: "search ran out of attempts", Attempts: attempts, Repairings: best.repairings(), Score: best.score}
+	}
+
+	return nil
+}
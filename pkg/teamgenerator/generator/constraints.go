@@ -0,0 +1,496 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// ConstraintConflictError is returned when the constraints on a grouping cannot all be satisfied
+type ConstraintConflictError struct {
+	// Conflicts describe the rules that cannot be satisfied together
+	Conflicts []string
+}
+
+func (e *ConstraintConflictError) Error() string {
+	return fmt.Sprintf("constraints cannot all be satisfied: %s", strings.Join(e.Conflicts, "; "))
+}
+
+// describeConstraint describes a rule so that it can be found in the file it came from
+func describeConstraint(constraint api.Constraint) string {
+	scope := "all projects"
+	if len(constraint.Projects) > 0 {
+		scope = strings.Join(constraint.Projects, ", ")
+	}
+	return fmt.Sprintf("%q", fmt.Sprintf("%s %s %s (%s)", constraint.Kind, constraint.Student, constraint.Partner, scope))
+}
+
+// describeConstraints describes a list of rules
+func describeConstraints(constraints []api.Constraint) string {
+	var descriptions []string
+	for _, constraint := range constraints {
+		descriptions = append(descriptions, describeConstraint(constraint))
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// appliesTo determines if a rule or preference for the given projects applies to the project. If no projects
+// are given, it applies to all of them.
+func appliesTo(projects []string, project string) bool {
+	if len(projects) == 0 {
+		return true
+	}
+	for _, name := range projects {
+		if name == project {
+			return true
+		}
+	}
+	return false
+}
+
+// component is a set of students that must all share a group, along with the rules that require it
+type component struct {
+	netIDs []string
+	rules  []api.Constraint
+}
+
+// projectConstraints are the rules that apply to one project
+type projectConstraints struct {
+	// components are the sets of students that must share a group, largest first
+	components []component
+
+	// apart are the rules that keep students out of each others' groups
+	apart []api.Constraint
+}
+
+// newProjectConstraints gathers the rules that apply to the project, joining students that must be together
+// into components
+func newProjectConstraints(constraints []api.Constraint, project string) projectConstraints {
+	componentOf := map[string]int{}
+	var components []component
+	for _, constraint := range constraints {
+		if !appliesTo(constraint.Projects, project) || constraint.Kind != api.MustBeTogether {
+			continue
+		}
+
+		first, firstFound := componentOf[constraint.Student]
+		second, secondFound := componentOf[constraint.Partner]
+		switch {
+		case firstFound && secondFound && first != second:
+			// merge the second component into the first
+			components[first].netIDs = append(components[first].netIDs, components[second].netIDs...)
+			components[first].rules = append(components[first].rules, components[second].rules...)
+			for _, netID := range components[second].netIDs {
+				componentOf[netID] = first
+			}
+			components[second] = component{}
+		case firstFound && !secondFound:
+			components[first].netIDs = append(components[first].netIDs, constraint.Partner)
+			componentOf[constraint.Partner] = first
+		case !firstFound && secondFound:
+			components[second].netIDs = append(components[second].netIDs, constraint.Student)
+			componentOf[constraint.Student] = second
+			first = second
+		case !firstFound && !secondFound:
+			first = len(components)
+			components = append(components, component{netIDs: []string{constraint.Student, constraint.Partner}})
+			componentOf[constraint.Student] = first
+			componentOf[constraint.Partner] = first
+		}
+		components[first].rules = append(components[first].rules, constraint)
+	}
+
+	var constraintsForProject projectConstraints
+	for _, component := range components {
+		if len(component.netIDs) > 0 {
+			constraintsForProject.components = append(constraintsForProject.components, component)
+		}
+	}
+	sort.SliceStable(constraintsForProject.components, func(i, j int) bool {
+		return len(constraintsForProject.components[i].netIDs) > len(constraintsForProject.components[j].netIDs)
+	})
+
+	for _, constraint := range constraints {
+		if appliesTo(constraint.Projects, project) && constraint.Kind == api.MustBeApart {
+			constraintsForProject.apart = append(constraintsForProject.apart, constraint)
+		}
+	}
+	return constraintsForProject
+}
+
+// packComponents assigns every component to a group with enough room for it, trying the groups in order and
+// placing the largest components first. The index of the group for every component is returned, or -1 for
+// those that did not fit.
+func packComponents(room []int, components []component) []int {
+	room = append([]int{}, room...)
+	var assignments []int
+	for _, component := range components {
+		assignment := -1
+		for i := range room {
+			if room[i] >= len(component.netIDs) {
+				room[i] -= len(component.netIDs)
+				assignment = i
+				break
+			}
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments
+}
+
+// apartGraph joins the students in a section that must be kept apart, with the students that must be together
+// standing in for one another as a single node
+type apartGraph struct {
+	// sizes are the number of students in every node, and together are the rules that joined them
+	sizes    []int
+	together [][]api.Constraint
+
+	// apart are the rules that keep every pair of nodes apart
+	apart []map[int][]api.Constraint
+}
+
+// newApartGraph builds the graph for the section from its components and the rules keeping its students apart.
+// Pinned students are left out, as nobody else can join their groups anyway.
+func newApartGraph(components []component, constraintsForProject projectConstraints, pinnedGroupOf map[string]int, sectionOf map[string]string, section string) *apartGraph {
+	graph := &apartGraph{}
+	nodeOf := map[string]int{}
+	addNode := func(size int, rules []api.Constraint) int {
+		graph.sizes = append(graph.sizes, size)
+		graph.together = append(graph.together, rules)
+		graph.apart = append(graph.apart, map[int][]api.Constraint{})
+		return len(graph.sizes) - 1
+	}
+	for _, component := range components {
+		node := addNode(len(component.netIDs), component.rules)
+		for _, netID := range component.netIDs {
+			nodeOf[netID] = node
+		}
+	}
+
+	// students in components that were left out have conflicts of their own, so their rules are not followed here
+	inComponent := map[string]bool{}
+	for _, component := range constraintsForProject.components {
+		for _, netID := range component.netIDs {
+			inComponent[netID] = true
+		}
+	}
+	nodeFor := func(netID string) (int, bool) {
+		if node, ok := nodeOf[netID]; ok {
+			return node, true
+		}
+		if _, pinned := pinnedGroupOf[netID]; pinned || inComponent[netID] || sectionOf[netID] != section {
+			return 0, false
+		}
+		nodeOf[netID] = addNode(1, nil)
+		return nodeOf[netID], true
+	}
+
+	for _, rule := range constraintsForProject.apart {
+		first, firstFound := nodeFor(rule.Student)
+		second, secondFound := nodeFor(rule.Partner)
+		if !firstFound || !secondFound || first == second {
+			continue
+		}
+		graph.apart[first][second] = append(graph.apart[first][second], rule)
+		graph.apart[second][first] = append(graph.apart[second][first], rule)
+	}
+	return graph
+}
+
+// cliques lists every largest set of nodes that must all be kept apart from each other, of two nodes or more
+func (a *apartGraph) cliques() [][]int {
+	var candidates []int
+	for node := range a.sizes {
+		if len(a.apart[node]) > 0 {
+			candidates = append(candidates, node)
+		}
+	}
+
+	var cliques [][]int
+	var extend func(clique, candidates, excluded []int)
+	extend = func(clique, candidates, excluded []int) {
+		if len(candidates) == 0 && len(excluded) == 0 {
+			cliques = append(cliques, append([]int{}, clique...))
+			return
+		}
+		// every largest clique includes the pivot or one of the nodes it is not kept apart from
+		pivot, pivotNeighbors := -1, -1
+		for _, node := range append(append([]int{}, candidates...), excluded...) {
+			if neighbors := len(a.within(node, candidates)); neighbors > pivotNeighbors {
+				pivot, pivotNeighbors = node, neighbors
+			}
+		}
+		for _, node := range append([]int{}, candidates...) {
+			if _, apart := a.apart[pivot][node]; apart {
+				continue
+			}
+			extend(append(clique, node), a.within(node, candidates), a.within(node, excluded))
+			candidates = withoutNode(candidates, node)
+			excluded = append(excluded, node)
+		}
+	}
+	extend(nil, candidates, nil)
+	return cliques
+}
+
+// within lists the nodes that must be kept apart from the node
+func (a *apartGraph) within(node int, nodes []int) []int {
+	var neighbors []int
+	for _, other := range nodes {
+		if _, apart := a.apart[node][other]; apart {
+			neighbors = append(neighbors, other)
+		}
+	}
+	return neighbors
+}
+
+// sizesOf lists the number of students in every node of the clique
+func (a *apartGraph) sizesOf(clique []int) []int {
+	var sizes []int
+	for _, node := range clique {
+		sizes = append(sizes, a.sizes[node])
+	}
+	return sizes
+}
+
+// rulesOf lists the rules that keep the nodes of the clique apart and the rules that hold each of them together
+func (a *apartGraph) rulesOf(clique []int) []api.Constraint {
+	var rules []api.Constraint
+	for i, node := range clique {
+		rules = append(rules, a.together[node]...)
+		for _, other := range clique[i+1:] {
+			rules = append(rules, a.apart[node][other]...)
+		}
+	}
+	return rules
+}
+
+// withoutNode lists the nodes other than the given one
+func withoutNode(nodes []int, node int) []int {
+	var remaining []int
+	for _, other := range nodes {
+		if other != node {
+			remaining = append(remaining, other)
+		}
+	}
+	return remaining
+}
+
+// fitsApart determines if sets of students of the given sizes can each be placed in a different group. Pairing
+// the largest sets with the largest groups works whenever any placement does.
+func fitsApart(sizes []int, groupSizes []int) bool {
+	if len(sizes) > len(groupSizes) {
+		return false
+	}
+	sizes = append([]int{}, sizes...)
+	groupSizes = append([]int{}, groupSizes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	sort.Sort(sort.Reverse(sort.IntSlice(groupSizes)))
+	for i := range sizes {
+		if sizes[i] > groupSizes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkConstraints determines if the constraints, including the sizes of groups, can all be satisfied for the
+// roster and projects, returning a *ConstraintConflictError naming the conflicting rules if they cannot
+func (g *classGrouping) checkConstraints(students []api.Student, groupingNames []string) error {
+	onRoster := map[string]bool{}
+	sectionOf := map[string]string{}
+	var sections []string
+	sectionSizes := map[string]int{}
+	for _, student := range students {
+		onRoster[student.NetID] = true
+		sectionOf[student.NetID] = student.Section
+		if _, seen := sectionSizes[student.Section]; !seen {
+			sections = append(sections, student.Section)
+		}
+		sectionSizes[student.Section]++
+	}
+
+	var conflicts []string
+	for _, constraint := range g.constraints {
+		for _, netID := range []string{constraint.Student, constraint.Partner} {
+			if !onRoster[netID] {
+				conflicts = append(conflicts, fmt.Sprintf("rule %s names %s, who is not on the roster", describeConstraint(constraint), netID))
+			}
+		}
+	}
+
+	for _, name := range groupingNames {
+		// pinned students are already grouped, so only the rest of the roster needs to be sized
+		pinnedGroupOf := map[string]int{}
+		leftoverSizes := map[string]int{}
+		for section, size := range sectionSizes {
+			leftoverSizes[section] = size
+		}
+		for i, group := range g.pinnedGroups[name] {
+			for _, member := range group.Members {
+				if _, pinned := pinnedGroupOf[member.NetID]; pinned {
+					conflicts = append(conflicts, fmt.Sprintf("for %s, %s is pinned to more than one group", name, member.NetID))
+					continue
+				}
+				if !onRoster[member.NetID] {
+					conflicts = append(conflicts, fmt.Sprintf("for %s, pinned group %d names %s, who is not on the roster", name, i+1, member.NetID))
+					continue
+				}
+				if sectionOf[member.NetID] != sectionOf[group.Members[0].NetID] {
+					conflicts = append(conflicts, fmt.Sprintf("for %s, pinned group %d has students from different sections", name, i+1))
+				}
+				pinnedGroupOf[member.NetID] = i
+				leftoverSizes[sectionOf[member.NetID]]--
+			}
+		}
+
+		constraintsForProject := newProjectConstraints(g.constraints, name)
+		for _, component := range constraintsForProject.components {
+			together := map[string]bool{}
+			for _, netID := range component.netIDs {
+				together[netID] = true
+			}
+			for _, apart := range constraintsForProject.apart {
+				if together[apart.Student] && together[apart.Partner] {
+					conflicts = append(conflicts, fmt.Sprintf("for %s, rule %s conflicts with rules %s", name, describeConstraint(apart), describeConstraints(component.rules)))
+				}
+			}
+		}
+		for _, apart := range constraintsForProject.apart {
+			first, firstPinned := pinnedGroupOf[apart.Student]
+			second, secondPinned := pinnedGroupOf[apart.Partner]
+			if firstPinned && secondPinned && first == second {
+				conflicts = append(conflicts, fmt.Sprintf("for %s, rule %s conflicts with pinned group %d", name, describeConstraint(apart), first+1))
+			}
+		}
+
+		// students can only be grouped within their section, so every section is packed on its own
+		componentsInSection := map[string][]component{}
+		for _, component := range constraintsForProject.components {
+			pinnedGroups, unpinned := map[int]bool{}, 0
+			for _, netID := range component.netIDs {
+				if group, pinned := pinnedGroupOf[netID]; pinned {
+					pinnedGroups[group] = true
+				} else {
+					unpinned++
+				}
+			}
+			if len(pinnedGroups) > 0 {
+				// pinned groups are complete, so nobody else can join them
+				if len(pinnedGroups) > 1 || unpinned > 0 {
+					conflicts = append(conflicts, fmt.Sprintf("for %s, rules %s require students to be together who are not pinned to the same group", name, describeConstraints(component.rules)))
+				}
+				continue
+			}
+
+			section := sectionOf[component.netIDs[0]]
+			sameSection := true
+			for _, netID := range component.netIDs {
+				if sectionOf[netID] != section {
+					sameSection = false
+				}
+			}
+			if !sameSection {
+				conflicts = append(conflicts, fmt.Sprintf("for %s, rules %s require students from different sections to be together", name, describeConstraints(component.rules)))
+				continue
+			}
+			componentsInSection[section] = append(componentsInSection[section], component)
+		}
+
+		for _, section := range sections {
+			if leftoverSizes[section] == 0 && sectionSizes[section] > 0 {
+				// everyone in the section is pinned
+				continue
+			}
+			groupSizes, err := g.sizingFor(name).groupSizes(leftoverSizes[section])
+			if err != nil {
+				conflicts = append(conflicts, fmt.Sprintf("for %s, %v", name, err))
+				continue
+			}
+			for i, assignment := range packComponents(groupSizes, componentsInSection[section]) {
+				if assignment < 0 {
+					component := componentsInSection[section][i]
+					conflicts = append(conflicts, fmt.Sprintf("for %s, rules %s require %d students to be together but groups of sizes %v cannot fit them", name, describeConstraints(component.rules), len(component.netIDs), groupSizes))
+				}
+			}
+
+			apart := newApartGraph(componentsInSection[section], constraintsForProject, pinnedGroupOf, sectionOf, section)
+			for _, clique := range apart.cliques() {
+				if !fitsApart(apart.sizesOf(clique), groupSizes) {
+					conflicts = append(conflicts, fmt.Sprintf("for %s, rules %s keep %d sets of students apart but groups of sizes %v cannot hold them all", name, describeConstraints(apart.rulesOf(clique)), len(clique), groupSizes))
+				}
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return &ConstraintConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// apartConflict names the rules keeping students apart in every project, for when a search finds that no grouping
+// follows them all even though no set of students kept apart was too large for the groups on its own
+func (o *options) apartConflict(groupingNames []string, outcome string) *ConstraintConflictError {
+	var conflicts []string
+	for _, name := range groupingNames {
+		if apart := newProjectConstraints(o.constraints, name).apart; len(apart) > 0 {
+			conflicts = append(conflicts, fmt.Sprintf("for %s, %s rules %s", name, outcome, describeConstraints(apart)))
+		}
+	}
+	return &ConstraintConflictError{Conflicts: conflicts}
+}
+
+// placeConstraints records the rules for the project and places students that must be together into groups,
+// locking them there. Groups of the same size are tried in a random order.
+func (g *classGrouping) placeConstraints(project *Project, associativeRoster map[string]*Student, random *rand.Rand) error {
+	constraintsForProject := newProjectConstraints(g.constraints, project.Name)
+	for _, apart := range constraintsForProject.apart {
+		project.Separate(associativeRoster[apart.Student], associativeRoster[apart.Partner])
+	}
+
+	groups := append([]*Group{}, project.Groups...)
+	random.Shuffle(len(groups), func(i, j int) {
+		groups[i], groups[j] = groups[j], groups[i]
+	})
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].DesiredSize > groups[j].DesiredSize
+	})
+
+	for _, component := range constraintsForProject.components {
+		var members []*Student
+		for _, netID := range component.netIDs {
+			members = append(members, associativeRoster[netID])
+		}
+		if project.IsLocked(members[0]) {
+			// the students were pinned together already
+			continue
+		}
+
+		placed := false
+		for _, group := range groups {
+			if group.DesiredSize-len(group.members) < len(members) || !project.CanJoinAll(group, members) {
+				continue
+			}
+			for _, member := range members {
+				group.AddMember(member)
+				project.MarkStudentGrouped(member)
+				project.Lock(member)
+			}
+			if project.explaining() {
+				for _, member := range members {
+					project.explain(member, "was placed in group %d, as rules %s keep them together", project.groupNumber(group), describeConstraints(component.rules))
+				}
+			}
+			placed = true
+			break
+		}
+		if !placed {
+			return fmt.Errorf("could not find a group for %s to satisfy rules %s", project.Name, describeConstraints(component.rules))
+		}
+	}
+	return nil
+}
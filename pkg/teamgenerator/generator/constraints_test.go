@@ -0,0 +1,229 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// apartRing keeps every one of the first n students apart from the next, and the last apart from the first
+func apartRing(n int) []api.Constraint {
+	var constraints []api.Constraint
+	for i := 0; i < n; i++ {
+		constraints = append(constraints, api.Constraint{Kind: api.MustBeApart, Student: fmt.Sprintf("s%d@duke.edu", i), Partner: fmt.Sprintf("s%d@duke.edu", (i+1)%n)})
+	}
+	return constraints
+}
+
+func TestCheckConstraints(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		constraints       []api.Constraint
+		expectedConflicts []string
+	}{
+		{
+			name: "satisfiable rules",
+			constraints: []api.Constraint{
+				{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s2@duke.edu"},
+			},
+			expectedConflicts: nil,
+		},
+		{
+			name: "apart conflicts with a chain of together",
+			constraints: []api.Constraint{
+				{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+				{Kind: api.MustBeTogether, Student: "s1@duke.edu", Partner: "s2@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s2@duke.edu", Partner: "s0@duke.edu", Projects: []string{"final"}},
+			},
+			expectedConflicts: []string{
+				`for final, rule "apart s2@duke.edu s0@duke.edu (final)" conflicts with rules "together s0@duke.edu s1@duke.edu (all projects)", "together s1@duke.edu s2@duke.edu (all projects)"`,
+			},
+		},
+		{
+			name: "rules for other projects do not conflict",
+			constraints: []api.Constraint{
+				{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s1@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s1@duke.edu", Projects: []string{"final"}},
+			},
+			expectedConflicts: nil,
+		},
+		{
+			name: "too many students together",
+			constraints: []api.Constraint{
+				{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s1@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeTogether, Student: "s1@duke.edu", Partner: "s2@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeTogether, Student: "s2@duke.edu", Partner: "s3@duke.edu", Projects: []string{"design"}},
+			},
+			expectedConflicts: []string{
+				`for design, rules "together s0@duke.edu s1@duke.edu (design)", "together s1@duke.edu s2@duke.edu (design)", "together s2@duke.edu s3@duke.edu (design)" require 4 students to be together but groups of sizes [3 3 3] cannot fit them`,
+			},
+		},
+		{
+			name: "more students kept apart than there are groups",
+			constraints: []api.Constraint{
+				{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s1@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s2@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeApart, Student: "s1@duke.edu", Partner: "s3@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s4@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeApart, Student: "s2@duke.edu", Partner: "s3@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeApart, Student: "s2@duke.edu", Partner: "s4@duke.edu", Projects: []string{"design"}},
+				{Kind: api.MustBeApart, Student: "s3@duke.edu", Partner: "s4@duke.edu", Projects: []string{"design"}},
+			},
+			expectedConflicts: []string{
+				`for design, rules "together s0@duke.edu s1@duke.edu (design)", "apart s0@duke.edu s2@duke.edu (design)", "apart s1@duke.edu s3@duke.edu (design)", "apart s0@duke.edu s4@duke.edu (design)", "apart s2@duke.edu s3@duke.edu (design)", "apart s2@duke.edu s4@duke.edu (design)", "apart s3@duke.edu s4@duke.edu (design)" keep 4 sets of students apart but groups of sizes [3 3 3] cannot hold them all`,
+			},
+		},
+		{
+			name: "students kept apart in as many groups as there are",
+			constraints: []api.Constraint{
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s2@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s1@duke.edu", Partner: "s2@duke.edu"},
+			},
+			expectedConflicts: nil,
+		},
+		{
+			name: "unknown student",
+			constraints: []api.Constraint{
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "xyz@duke.edu"},
+			},
+			expectedConflicts: []string{
+				`rule "apart s0@duke.edu xyz@duke.edu (all projects)" names xyz@duke.edu, who is not on the roster`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		generator := &classGrouping{optimalGroupSize: 3, options: newOptions([]Option{WithConstraints(testCase.constraints)})}
+		err := generator.checkConstraints(testRoster(9), []string{"design", "final"})
+
+		var actualConflicts []string
+		if err != nil {
+			conflict, ok := err.(*ConstraintConflictError)
+			if !ok {
+				t.Errorf("%s: expected a constraint conflict, got %v", testCase.name, err)
+				continue
+			}
+			actualConflicts = conflict.Conflicts
+		}
+
+		if !reflect.DeepEqual(actualConflicts, testCase.expectedConflicts) {
+			t.Errorf("%s: correct conflicts not found:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedConflicts, actualConflicts)
+		}
+	}
+}
+
+func TestGenerateFollowsConstraints(t *testing.T) {
+	roster := testRoster(12)
+	projects := []string{"design", "final"}
+	constraints := []api.Constraint{
+		{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+		{Kind: api.MustBeTogether, Student: "s2@duke.edu", Partner: "s3@duke.edu", Projects: []string{"final"}},
+		{Kind: api.MustBeApart, Student: "s4@duke.edu", Partner: "s5@duke.edu"},
+		{Kind: api.MustBeApart, Student: "s4@duke.edu", Partner: "s6@duke.edu"},
+	}
+	schedule := Schedule{InitialTemperature: 1, CoolingRate: 0.8, MinimumTemperature: 0.1, SwapsPerTemperature: 50}
+
+	generators := map[string]ClassGrouping{
+		"reshuffle": NewClassGrouping(3, false, WithSeed(42), WithConstraints(constraints)),
+		"annealing": NewAnnealingClassGrouping(3, false, schedule, WithSeed(42), WithConstraints(constraints)),
+	}
+
+	for name, generator := range generators {
+		grouping, err := generator.GenerateContext(context.Background(), roster, projects, Budget{})
+		if err != nil {
+			t.Errorf("%s: failed to generate grouping: %v", name, err)
+			continue
+		}
+		checkEveryStudentGroupedOnce(t, name, roster, grouping)
+
+		for _, project := range grouping.Projects {
+			groupOf := map[string]int{}
+			for i, group := range project.Groups {
+				for _, member := range group.Members {
+					groupOf[member.NetID] = i
+				}
+			}
+
+			for _, constraint := range constraints {
+				if !appliesTo(constraint.Projects, project.Name) {
+					continue
+				}
+				together := groupOf[constraint.Student] == groupOf[constraint.Partner]
+				if together != (constraint.Kind == api.MustBeTogether) {
+					t.Errorf("%s: rule %s was broken for %s", name, describeConstraint(constraint), project.Name)
+				}
+			}
+		}
+	}
+}
+
+func TestCheckConstraintsAcrossSections(t *testing.T) {
+	roster := sectionedRoster(map[string]int{"01": 6, "02": 6})
+	constraints := []api.Constraint{
+		{Kind: api.MustBeTogether, Student: "s010@duke.edu", Partner: "s020@duke.edu"},
+		{Kind: api.MustBeTogether, Student: "s011@duke.edu", Partner: "s012@duke.edu", Projects: []string{"design"}},
+	}
+
+	generator := &classGrouping{optimalGroupSize: 3, options: newOptions([]Option{WithConstraints(constraints)})}
+	err := generator.checkConstraints(roster, []string{"design"})
+	expected := &ConstraintConflictError{Conflicts: []string{
+		`for design, rules "together s010@duke.edu s020@duke.edu (all projects)" require students from different sections to be together`,
+	}}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("correct conflicts not found:\n\twanted:\n\t%v\n\tgot:\n\t%v", expected, err)
+	}
+}
+
+func TestGenerateRefusesStudentsKeptApartInTooFewGroups(t *testing.T) {
+	var testCases = []struct {
+		name        string
+		numStudents int
+		groupSize   int
+		constraints []api.Constraint
+	}{
+		{
+			name:        "three students kept apart in two groups",
+			numStudents: 4,
+			groupSize:   2,
+			constraints: []api.Constraint{
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s2@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s1@duke.edu", Partner: "s2@duke.edu"},
+			},
+		},
+		{
+			name:        "three pairs kept apart in two groups",
+			numStudents: 8,
+			groupSize:   4,
+			constraints: []api.Constraint{
+				{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+				{Kind: api.MustBeTogether, Student: "s2@duke.edu", Partner: "s3@duke.edu"},
+				{Kind: api.MustBeTogether, Student: "s4@duke.edu", Partner: "s5@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s2@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s4@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s2@duke.edu", Partner: "s4@duke.edu"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		generator := NewClassGrouping(testCase.groupSize, false, WithSeed(1), WithConstraints(testCase.constraints))
+		_, err := generator.GenerateContext(context.Background(), testRoster(testCase.numStudents), []string{"design"}, Budget{})
+		conflict, ok := err.(*ConstraintConflictError)
+		if !ok {
+			t.Errorf("%s: expected a constraint conflict, got %v", testCase.name, err)
+			continue
+		}
+		for _, constraint := range testCase.constraints {
+			if !strings.Contains(conflict.Error(), describeConstraint(constraint)) {
+				t.Errorf("%s: expected the conflict to name rule %s, got %v", testCase.name, describeConstraint(constraint), conflict)
+			}
+		}
+	}
+}
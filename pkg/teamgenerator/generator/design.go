@@ -0,0 +1,319 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// designMappings is the number of random mappings of students onto a design that are tried when prior groupings
+// need to be avoided
+const designMappings = 1000
+
+// design is a schedule of rounds, each of which splits the points of the design into blocks.
+// No two points share a block in more than one round.
+type design struct {
+	// name describes the construction that created the design
+	name string
+
+	// rounds hold the blocks of points for each round
+	rounds [][][]int
+}
+
+// MaxRepeatFreeProjects determines an upper bound on the number of projects the class can be grouped for without
+// any two students collaborating twice: every project uses up pairs of students, and the class only has so many.
+// When groups are all the same size, this is the same as every student running out of new partners. A second
+// project is only possible if there are at least as many groups as members in the largest of them, as everyone in
+// a group for the second project must come from a different group in the first. Fewer projects than the bound may
+// be possible. If no pairs are made at all, -1 is returned as there is no bound.
+func MaxRepeatFreeProjects(numStudents, optimalGroupSize int, preferSmallerGroups bool) int {
+	if numStudents < 2 {
+		return -1
+	}
+
+	sizes := determineGroupSizes(numStudents, optimalGroupSize, preferSmallerGroups)
+	pairsPerProject, largest := 0, 0
+	for _, size := range sizes {
+		pairsPerProject += size * (size - 1) / 2
+		largest = max(largest, size)
+	}
+	if pairsPerProject == 0 {
+		return -1
+	}
+
+	if len(sizes) < largest {
+		return 1
+	}
+	return numStudents * (numStudents - 1) / 2 / pairsPerProject
+}
+
+// knownDesign builds a repeat-free design with at least the given number of rounds, splitting the given number of
+// points into blocks of the given size, if a construction for one is known
+func knownDesign(numPoints, blockSize, numRounds int) (design, bool) {
+	var candidates []design
+	if blockSize == 2 && numPoints%2 == 0 {
+		candidates = append(candidates, roundRobinDesign(numPoints))
+	}
+	if dimension, ok := primePowerOf(numPoints, blockSize); ok {
+		candidates = append(candidates, affineDesign(blockSize, dimension))
+	}
+	if numPoints%blockSize == 0 && blockSize <= smallestPrimeFactor(numPoints/blockSize) {
+		candidates = append(candidates, transversalDesign(blockSize, numPoints/blockSize))
+	}
+	if numPoints == 15 && blockSize == 3 {
+		candidates = append(candidates, kirkmanDesign())
+	}
+
+	best := -1
+	for i, candidate := range candidates {
+		if best < 0 || len(candidate.rounds) > len(candidates[best].rounds) {
+			best = i
+		}
+	}
+	if best < 0 || len(candidates[best].rounds) < numRounds {
+		return design{}, false
+	}
+
+	chosen := candidates[best]
+	chosen.rounds = chosen.rounds[:numRounds]
+	return chosen, true
+}
+
+// roundRobinDesign pairs up an even number of points using the circle method: one point stays fixed while the
+// others rotate around it, giving a new partner to everyone in every round
+func roundRobinDesign(numPoints int) design {
+	rotating := numPoints - 1
+	var rounds [][][]int
+	for round := 0; round < rotating; round++ {
+		blocks := [][]int{{round, rotating}}
+		for offset := 1; offset < numPoints/2; offset++ {
+			blocks = append(blocks, []int{(round + offset) % rotating, (round - offset + rotating) % rotating})
+		}
+		rounds = append(rounds, blocks)
+	}
+	return design{name: fmt.Sprintf("round robin on %d students", numPoints), rounds: rounds}
+}
+
+// affineDesign builds the affine geometry over the integers modulo a prime, where points are vectors with the
+// given number of dimensions and blocks are lines. Every direction gives a round of parallel lines and any two
+// points share exactly one line.
+func affineDesign(prime, dimension int) design {
+	numPoints := 1
+	for i := 0; i < dimension; i++ {
+		numPoints *= prime
+	}
+
+	var rounds [][][]int
+	for direction := 1; direction < numPoints; direction++ {
+		// every direction is a multiple of one whose most significant non-zero coordinate is one,
+		// so we only need to build lines in those directions
+		if leadingDigit(direction, prime) != 1 {
+			continue
+		}
+
+		block := make([]int, numPoints)
+		for i := range block {
+			block[i] = -1
+		}
+		var blocks [][]int
+		for point := 0; point < numPoints; point++ {
+			if block[point] >= 0 {
+				continue
+			}
+			var line []int
+			for next, step := point, 0; step < prime; next, step = addVectors(next, direction, prime), step+1 {
+				block[next] = len(blocks)
+				line = append(line, next)
+			}
+			blocks = append(blocks, line)
+		}
+		rounds = append(rounds, blocks)
+	}
+	return design{name: fmt.Sprintf("affine geometry AG(%d,%d)", dimension, prime), rounds: rounds}
+}
+
+// transversalDesign splits points into rows of the block size and columns of the number of blocks. In every round,
+// a point moves to the block given by its column shifted by its row times the round. As long as every row difference
+// has an inverse modulo the number of blocks, any two points in different rows meet in exactly one round and points
+// in the same row never meet.
+func transversalDesign(blockSize, numBlocks int) design {
+	var rounds [][][]int
+	for round := 0; round < numBlocks; round++ {
+		blocks := make([][]int, numBlocks)
+		for row := 0; row < blockSize; row++ {
+			for column := 0; column < numBlocks; column++ {
+				index := (column + round*row) % numBlocks
+				blocks[index] = append(blocks[index], row*numBlocks+column)
+			}
+		}
+		rounds = append(rounds, blocks)
+	}
+	return design{name: fmt.Sprintf("transversal design on %d groups of %d", numBlocks, blockSize), rounds: rounds}
+}
+
+// kirkmanDesign is a solution to Kirkman's schoolgirl problem: fifteen students in groups of three for seven rounds
+func kirkmanDesign() design {
+	return design{name: "Kirkman triple system on 15 students", rounds: [][][]int{
+		{{0, 1, 2}, {3, 4, 5}, {6, 7, 8}, {9, 10, 11}, {12, 13, 14}},
+		{{0, 3, 6}, {1, 4, 7}, {2, 9, 12}, {5, 10, 13}, {8, 11, 14}},
+		{{0, 4, 9}, {1, 5, 11}, {2, 7, 14}, {3, 8, 13}, {6, 10, 12}},
+		{{0, 5, 14}, {1, 3, 12}, {2, 6, 11}, {4, 8, 10}, {7, 9, 13}},
+		{{0, 7, 10}, {1, 6, 13}, {2, 5, 8}, {3, 9, 14}, {4, 11, 12}},
+		{{0, 8, 12}, {1, 10, 14}, {2, 4, 13}, {3, 7, 11}, {5, 6, 9}},
+		{{0, 11, 13}, {1, 8, 9}, {2, 3, 10}, {4, 6, 14}, {5, 7, 12}},
+	}}
+}
+
+// primePowerOf determines if the number is a power of the prime, and to which power
+func primePowerOf(number, prime int) (int, bool) {
+	if prime < 2 || smallestPrimeFactor(prime) != prime {
+		return 0, false
+	}
+
+	power := 0
+	for number > 1 && number%prime == 0 {
+		number /= prime
+		power++
+	}
+	return power, number == 1 && power > 0
+}
+
+// smallestPrimeFactor determines the smallest prime factor of the number
+func smallestPrimeFactor(number int) int {
+	for factor := 2; factor*factor <= number; factor++ {
+		if number%factor == 0 {
+			return factor
+		}
+	}
+	return number
+}
+
+// leadingDigit determines the most significant non-zero digit of the number in the given base
+func leadingDigit(number, base int) int {
+	for number >= base {
+		number /= base
+	}
+	return number
+}
+
+// addVectors adds two numbers digit by digit in the given base, without carrying
+func addVectors(first, second, base int) int {
+	sum, place := 0, 1
+	for first > 0 || second > 0 {
+		sum += ((first%base + second%base) % base) * place
+		first, second, place = first/base, second/base, place*base
+	}
+	return sum
+}
+
+// NewDesignClassGrouping returns a ClassGrouping that builds groupings directly from a known repeat-free design,
+// like a round robin, an affine geometry or a Kirkman triple system, when one exists for the size of the class,
+// the size of the groups and the number of projects. Otherwise, the fallback is used to search for a grouping.
+func NewDesignClassGrouping(optimalGroupSize int, preferSmallerGroups bool, fallback ClassGrouping, opts ...Option) ClassGrouping {
+	return &designClassGrouping{
+		classGrouping: classGrouping{optimalGroupSize: optimalGroupSize, preferSmallerGroups: preferSmallerGroups, options: newOptions(opts)},
+		fallback:      fallback,
+	}
+}
+
+type designClassGrouping struct {
+	classGrouping
+	fallback ClassGrouping
+}
+
+// Generate generates a class grouping from a roster
+func (g *designClassGrouping) Generate(students []api.Student, groupingNames []string) api.ClassGrouping {
+	return g.GenerateWithPriors(students, nil, groupingNames)
+}
+
+// GenerateWithPriors generates a class grouping from a roster, taking into account prior groupings
+func (g *designClassGrouping) GenerateWithPriors(students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string) api.ClassGrouping {
+	grouping, _ := g.GenerateWithPriorsContext(context.Background(), students, priorGroupings, groupingNames, Budget{})
+	return grouping
+}
+
+// GenerateContext generates a class grouping from a roster, within the given budget
+func (g *designClassGrouping) GenerateContext(ctx context.Context, students []api.Student, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	return g.GenerateWithPriorsContext(ctx, students, nil, groupingNames, budget)
+}
+
+// GenerateWithPriorsContext generates a class grouping from a roster, taking into account prior groupings,
+// within the given budget. When there are constraints, pinned groups or sections, the fallback is always used.
+// Students are mapped onto the design at random; if that can't avoid all of the prior collaborations, the
+// fallback is asked for a grouping as well and the better of the two is kept.
+func (g *designClassGrouping) GenerateWithPriorsContext(ctx context.Context, students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	if len(g.constraints) > 0 || len(g.pinnedGroups) > 0 {
+		// mapping students onto a design at random is very unlikely to keep the right students together
+		return g.fallback.GenerateWithPriorsContext(ctx, students, priorGroupings, groupingNames, budget)
+	}
+
+	for _, student := range students {
+		if student.Section != students[0].Section {
+			// designs are built for the whole class, which would group students across sections
+			return g.fallback.GenerateWithPriorsContext(ctx, students, priorGroupings, groupingNames, budget)
+		}
+	}
+
+	// a design has blocks of one size for every round, so every project needs the same, even group sizes
+	var groupSizes []int
+	for _, name := range groupingNames {
+		sizes, err := g.sizingFor(name).groupSizes(len(students))
+		if err != nil || len(sizes) == 0 || sizes[0] != sizes[len(sizes)-1] || len(groupSizes) > 0 && sizes[0] != groupSizes[0] {
+			return g.fallback.GenerateWithPriorsContext(ctx, students, priorGroupings, groupingNames, budget)
+		}
+		groupSizes = sizes
+	}
+
+	chosen, ok := knownDesign(len(students), groupSizes[0], len(groupingNames))
+	if !ok {
+		return g.fallback.GenerateWithPriorsContext(ctx, students, priorGroupings, groupingNames, budget)
+	}
+
+	random, seed := g.newRandom()
+	best := newBestGrouping(seed, &g.options)
+	mappings := 1
+	if len(priorGroupings) > 0 {
+		mappings = designMappings
+	}
+	attempts := 0
+	for ; attempts < mappings; attempts++ {
+		if best.found && (best.score <= 0 || ctx.Err() != nil) {
+			break
+		}
+
+		_, projects, _ := g.newProjects(students, priorGroupings, groupingNames, random)
+		applyDesign(chosen, projects, random.Perm(len(students)))
+		best.consider(projects, g.score(projects))
+	}
+	best.grouping.Summary.Construction = chosen.name
+
+	if best.score <= 0 {
+		fmt.Printf("Succeeded at creating groupings from the %s\n", chosen.name)
+		return best.result(attempts, nil)
+	}
+
+	fallback, err := g.fallback.GenerateWithPriorsContext(ctx, students, priorGroupings, groupingNames, budget)
+	if foundGrouping(fallback, err) && fallback.Summary.Score < best.score {
+		return fallback, err
+	}
+	return best.result(attempts, nil)
+}
+
+// applyDesign groups the students for every project according to a round of the design, where the student at every
+// index of the roster is mapped to the point at the same index of the mapping
+func applyDesign(chosen design, projects []*Project, mapping []int) {
+	for round, project := range projects {
+		students := make([]*Student, len(project.UngroupedStudents))
+		for i, student := range project.UngroupedStudents {
+			students[mapping[i]] = student
+		}
+
+		for i, block := range chosen.rounds[round] {
+			for _, point := range block {
+				project.Groups[i].AddMember(students[point])
+				project.MarkStudentGrouped(students[point])
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"context"
+	"testing"
+)
+
+// checkDesign ensures that every round of the design splits the points into blocks of the given size
+// and that no two points share a block more than once
+func checkDesign(t *testing.T, name string, chosen design, numPoints, blockSize int) {
+	met := map[[2]int]int{}
+	for i, round := range chosen.rounds {
+		seen := map[int]bool{}
+		for _, block := range round {
+			if len(block) != blockSize {
+				t.Errorf("%s: round %d has a block of size %d, expected %d", name, i, len(block), blockSize)
+			}
+			for j, point := range block {
+				seen[point] = true
+				for _, other := range block[j+1:] {
+					first, second := point, other
+					if second < first {
+						first, second = second, first
+					}
+					met[[2]int{first, second}]++
+				}
+			}
+		}
+		if len(seen) != numPoints {
+			t.Errorf("%s: round %d covers %d points, expected %d", name, i, len(seen), numPoints)
+		}
+	}
+
+	for pair, count := range met {
+		if count > 1 {
+			t.Errorf("%s: points %d and %d share a block %d times", name, pair[0], pair[1], count)
+		}
+	}
+}
+
+func TestKnownDesigns(t *testing.T) {
+	var testCases = []struct {
+		name           string
+		numPoints      int
+		blockSize      int
+		expectedRounds int
+	}{
+		{name: "round robin", numPoints: 10, blockSize: 2, expectedRounds: 9},
+		{name: "affine plane", numPoints: 25, blockSize: 5, expectedRounds: 6},
+		{name: "affine space", numPoints: 27, blockSize: 3, expectedRounds: 13},
+		{name: "transversal design", numPoints: 21, blockSize: 3, expectedRounds: 7},
+		{name: "transversal design on a composite number of groups", numPoints: 75, blockSize: 3, expectedRounds: 25},
+		{name: "Kirkman triple system", numPoints: 15, blockSize: 3, expectedRounds: 7},
+	}
+
+	for _, testCase := range testCases {
+		chosen, ok := knownDesign(testCase.numPoints, testCase.blockSize, testCase.expectedRounds)
+		if !ok {
+			t.Errorf("%s: expected a design with %d rounds to be known", testCase.name, testCase.expectedRounds)
+			continue
+		}
+		if len(chosen.rounds) != testCase.expectedRounds {
+			t.Errorf("%s: expected %d rounds, got %d", testCase.name, testCase.expectedRounds, len(chosen.rounds))
+		}
+		checkDesign(t, testCase.name, chosen, testCase.numPoints, testCase.blockSize)
+
+		if _, ok := knownDesign(testCase.numPoints, testCase.blockSize, testCase.expectedRounds+1); ok {
+			t.Errorf("%s: did not expect a design with %d rounds to be known", testCase.name, testCase.expectedRounds+1)
+		}
+	}
+}
+
+func TestMaxRepeatFreeProjects(t *testing.T) {
+	var testCases = []struct {
+		name                string
+		numStudents         int
+		optimalGroupSize    int
+		preferSmallerGroups bool
+		expectedMax         int
+	}{
+		{name: "pairs", numStudents: 10, optimalGroupSize: 2, expectedMax: 9},
+		{name: "triples", numStudents: 15, optimalGroupSize: 3, expectedMax: 7},
+		{name: "uneven groups", numStudents: 62, optimalGroupSize: 3, expectedMax: 31},
+		{name: "uneven groups, prefer larger", numStudents: 21, optimalGroupSize: 4, expectedMax: 6},
+		{name: "uneven groups, prefer smaller", numStudents: 21, optimalGroupSize: 4, preferSmallerGroups: true, expectedMax: 7},
+		{name: "everyone in one group", numStudents: 3, optimalGroupSize: 4, expectedMax: 1},
+		{name: "two groups of three", numStudents: 6, optimalGroupSize: 3, expectedMax: 1},
+		{name: "fewer groups than members of the largest", numStudents: 10, optimalGroupSize: 3, expectedMax: 1},
+		{name: "as many groups as members", numStudents: 9, optimalGroupSize: 3, expectedMax: 4},
+		{name: "nobody to pair", numStudents: 1, optimalGroupSize: 3, expectedMax: -1},
+	}
+
+	for _, testCase := range testCases {
+		if actual, expected := MaxRepeatFreeProjects(testCase.numStudents, testCase.optimalGroupSize, testCase.preferSmallerGroups), testCase.expectedMax; actual != expected {
+			t.Errorf("%s: expected a bound of %d repeat-free projects, got %d", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestDesignClassGrouping(t *testing.T) {
+	roster := testRoster(15)
+	projects := []string{"1", "2", "3", "4", "5", "6", "7"}
+	generator := NewDesignClassGrouping(3, false, NewClassGrouping(3, false), WithSeed(42))
+
+	grouping, err := generator.GenerateContext(context.Background(), roster, projects, Budget{})
+	if err != nil {
+		t.Fatalf("failed to generate grouping: %v", err)
+	}
+	checkEveryStudentGroupedOnce(t, "design", roster, grouping)
+
+	if grouping.Summary.Repairings != 0 {
+		t.Errorf("expected a grouping without repairings, got %d", grouping.Summary.Repairings)
+	}
+	if grouping.Summary.Construction == "" {
+		t.Errorf("expected the grouping to be built from a design")
+	}
+}
@@ -0,0 +1,395 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+const (
+	// MaxExactClassSize is the largest class the exact search will group, as it has to rule out every other grouping
+	MaxExactClassSize = 24
+
+	// DefaultExactTimeout is how long the exact search runs when the budget has no timeout, as proving a grouping
+	// optimal can take far longer than any class can wait once repeats can't be avoided, even for small classes
+	DefaultExactTimeout = time.Minute
+
+	// exactCheckInterval is the number of steps the exact search takes between checks of its context
+	exactCheckInterval = 1024
+)
+
+// NewExactClassGrouping returns a ClassGrouping that searches every grouping of a small class with branch and bound to
+// find one with the fewest repairings across all of the projects. When the search finishes, the grouping is proven
+// to be optimal; if it is stopped early, the best grouping found is returned along with the fewest repairings any
+// grouping could have, as far as the search got. Unless the budget has a timeout, the search is stopped after
+// DefaultExactTimeout. Other objectives are scored, but are not optimized.
+func NewExactClassGrouping(optimalGroupSize int, preferSmallerGroups bool, opts ...Option) ClassGrouping {
+	return &exactClassGrouping{
+		classGrouping:  classGrouping{optimalGroupSize: optimalGroupSize, preferSmallerGroups: preferSmallerGroups, options: newOptions(opts)},
+		defaultTimeout: DefaultExactTimeout,
+	}
+}
+
+type exactClassGrouping struct {
+	classGrouping
+
+	// defaultTimeout is how long the search runs when the budget has no timeout
+	defaultTimeout time.Duration
+}
+
+// Generate generates a class grouping from a roster
+func (g *exactClassGrouping) Generate(students []api.Student, groupingNames []string) api.ClassGrouping {
+	return g.GenerateWithPriors(students, nil, groupingNames)
+}
+
+// GenerateWithPriors generates a class grouping from a roster, taking into account prior groupings
+func (g *exactClassGrouping) GenerateWithPriors(students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string) api.ClassGrouping {
+	grouping, _ := g.GenerateWithPriorsContext(context.Background(), students, priorGroupings, groupingNames, Budget{})
+	return grouping
+}
+
+// GenerateContext generates a class grouping from a roster, within the given budget
+func (g *exactClassGrouping) GenerateContext(ctx context.Context, students []api.Student, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	return g.GenerateWithPriorsContext(ctx, students, nil, groupingNames, budget)
+}
+
+// GenerateWithPriorsContext generates a class grouping from a roster, taking into account prior groupings,
+// within the given budget. The search is a single attempt, so only the wall-clock budget applies.
+func (g *exactClassGrouping) GenerateWithPriorsContext(ctx context.Context, students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	if len(students) > MaxExactClassSize {
+		return api.ClassGrouping{}, fmt.Errorf("the exact search can only group classes of up to %d students, got %d", MaxExactClassSize, len(students))
+	}
+	if err := g.checkConstraints(students, groupingNames); err != nil {
+		return api.ClassGrouping{}, err
+	}
+
+	if budget.Timeout == 0 {
+		budget.Timeout = g.defaultTimeout
+	}
+	ctx, cancel := budget.apply(ctx)
+	defer cancel()
+
+	random, seed := g.newRandom()
+	_, projects, err := g.newProjects(students, priorGroupings, groupingNames, random)
+	if err != nil {
+		return api.ClassGrouping{}, err
+	}
+
+	search := newExactSearch(ctx, projects)
+	lowerBound := min(search.branch(0, partnerBounds{project: -1}), search.incumbent)
+
+	best := newBestGrouping(seed, &g.options)
+	if search.solution != nil {
+		search.restore()
+		best.consider(projects, g.score(projects))
+	}
+	if search.stopped {
+		best.grouping.Summary.LowerBound = lowerBound
+		return best.result(1, stopSearch(ctx, budget, 1, best))
+	}
+	if search.solution == nil {
+		return api.ClassGrouping{}, g.apartConflict(groupingNames, "no grouping follows")
+	}
+
+	fmt.Printf("Proved that the fewest repairings possible are %d after %d steps\n", search.incumbent, search.steps)
+	best.grouping.Summary.Optimal = true
+	best.grouping.Summary.LowerBound = search.incumbent
+	return best.result(1, nil)
+}
+
+// exactSearch places students into groups one at a time, backtracking to try every placement that could lead to
+// fewer repairings than the best grouping found so far
+type exactSearch struct {
+	ctx      context.Context
+	projects []*Project
+
+	// incumbent is the number of repairings in the best grouping found so far, and solution holds its groups
+	incumbent int
+	solution  [][][]*Student
+
+	// steps is the number of placements tried so far
+	steps int
+
+	// stopped is set once the context is done, after which nothing more is explored
+	stopped bool
+
+	// classmates are the students in every section of the class
+	classmates map[string][]*Student
+
+	// smallestGroups are the sizes of the smallest groups in every section, for every project
+	smallestGroups []map[string]int
+}
+
+func newExactSearch(ctx context.Context, projects []*Project) *exactSearch {
+	search := &exactSearch{ctx: ctx, projects: projects, incumbent: math.MaxInt, classmates: map[string][]*Student{}}
+	for i, project := range projects {
+		search.smallestGroups = append(search.smallestGroups, map[string]int{})
+		for _, group := range project.Groups {
+			if smallest, ok := search.smallestGroups[i][group.Section]; !ok || group.DesiredSize < smallest {
+				search.smallestGroups[i][group.Section] = group.DesiredSize
+			}
+			if i == 0 {
+				for _, member := range group.members {
+					search.classmates[member.Section] = append(search.classmates[member.Section], member)
+				}
+			}
+		}
+		if i == 0 {
+			for _, student := range project.UngroupedStudents {
+				search.classmates[student.Section] = append(search.classmates[student.Section], student)
+			}
+		}
+	}
+	return search
+}
+
+// placement is a group a student could be placed in
+type placement struct {
+	group *Group
+
+	// bound is the fewest repairings any grouping with the student in this group could have
+	bound int
+
+	// cost is the fewest repairings placing the students in this project could add, which breaks ties in the bound
+	cost int
+}
+
+// partnerBounds are the bounds from partnerBound, worked out when the search started placing students in a project
+type partnerBounds struct {
+	project int
+
+	// future is the fewest repairings the projects after this one could add
+	future int
+
+	// floor is the fewest repairings any grouping could have, counting those made before the project started
+	floor int
+}
+
+// branch tries every placement for the student that is hardest to place, given the repairings made so far. It returns
+// the fewest repairings any grouping in the part of the search that was left unexplored, because the search was stopped,
+// could have.
+func (s *exactSearch) branch(repairings int, bounds partnerBounds) int {
+	s.steps++
+	if s.steps%exactCheckInterval == 0 && s.ctx.Err() != nil {
+		s.stopped = true
+	}
+
+	current := s.currentProject()
+	if current == len(s.projects) {
+		if repairings < s.incumbent {
+			s.incumbent = repairings
+			s.record()
+		}
+		return math.MaxInt
+	}
+
+	project := s.projects[current]
+	student := s.hardestToPlace(project)
+	if student == nil {
+		return math.MaxInt
+	}
+
+	// these bounds only get tighter as students are placed, so they are only worked out once for every project
+	if bounds.project != current {
+		bounds = partnerBounds{
+			project: current,
+			future:  s.partnerBound(current, false),
+			floor:   repairings + s.partnerBound(current, true),
+		}
+	}
+
+	var placements []placement
+	seenEmpty := map[emptyGroup]bool{}
+	for _, group := range project.Groups {
+		if group.IsFull() || !project.CanJoin(group, student) {
+			continue
+		}
+		if len(group.members) == 0 {
+			// empty groups of the same size in the same section are interchangeable, so only the first is tried
+			kind := emptyGroup{size: group.DesiredSize, section: group.Section}
+			if seenEmpty[kind] {
+				continue
+			}
+			seenEmpty[kind] = true
+		}
+
+		added := group.AddMember(student)
+		project.MarkStudentGrouped(student)
+		if placing, feasible := placementBound(project); feasible {
+			bound := max(repairings+added+placing+bounds.future, bounds.floor)
+			placements = append(placements, placement{group: group, bound: bound, cost: added + placing})
+		}
+		group.RemoveMember(student)
+		project.MarkStudentUngrouped(student)
+	}
+	sort.SliceStable(placements, func(i, j int) bool {
+		if placements[i].bound != placements[j].bound {
+			return placements[i].bound < placements[j].bound
+		}
+		return placements[i].cost < placements[j].cost
+	})
+
+	unexplored := math.MaxInt
+	for _, next := range placements {
+		if next.bound >= s.incumbent {
+			// the placements are in order, so none of the rest can do better either
+			break
+		}
+		if s.stopped {
+			unexplored = min(unexplored, next.bound)
+			continue
+		}
+
+		added := next.group.AddMember(student)
+		project.MarkStudentGrouped(student)
+		unexplored = min(unexplored, s.branch(repairings+added, bounds))
+		next.group.RemoveMember(student)
+		project.MarkStudentUngrouped(student)
+	}
+	return unexplored
+}
+
+// emptyGroup describes the empty groups a student could be placed in interchangeably
+type emptyGroup struct {
+	size    int
+	section string
+}
+
+// currentProject is the index of the first project with students left to place, or the number of projects if
+// every student has been placed
+func (s *exactSearch) currentProject() int {
+	current := 0
+	for current < len(s.projects) && len(s.projects[current].UngroupedStudents) == 0 {
+		current++
+	}
+	return current
+}
+
+// hardestToPlace chooses the student left to place in the project who will repeat the most collaborations wherever
+// they go, or who can go in the fewest groups if that is the same. It returns nil if some student can't be placed.
+func (s *exactSearch) hardestToPlace(project *Project) *Student {
+	var hardest *Student
+	hardestCost, hardestOptions := -1, 0
+	for _, student := range project.UngroupedStudents {
+		cost, options := placementCost(project, student)
+		if options == 0 {
+			return nil
+		}
+		if cost > hardestCost || cost == hardestCost && options < hardestOptions {
+			hardest, hardestCost, hardestOptions = student, cost, options
+		}
+	}
+	return hardest
+}
+
+// placementBound determines the fewest repairings placing the rest of the students in the project could add, and
+// whether they can all be placed at all. Every student left will repeat at least as many collaborations with the
+// members already in the group they join as they would with the members of the group that is cheapest for them now.
+// Collaborations between students who are both left to place are not counted, so the bound never overestimates.
+func placementBound(project *Project) (int, bool) {
+	bound := 0
+	for _, student := range project.UngroupedStudents {
+		cost, options := placementCost(project, student)
+		if options == 0 {
+			return 0, false
+		}
+		bound += cost
+	}
+	return bound, true
+}
+
+// partnerBound determines the fewest repairings the projects after the current one could add, or every project
+// left including the current one. Every student needs a partner for every other place in their group that is left,
+// but they only have so many classmates they haven't collaborated with yet, so the rest of their partners have to
+// be repeats. Every repeat is shared by two students.
+func (s *exactSearch) partnerBound(current int, includeCurrent bool) int {
+	groupOf := map[*Student]*Group{}
+	for _, group := range s.projects[current].Groups {
+		for _, member := range group.members {
+			groupOf[member] = group
+		}
+	}
+
+	deficit := 0
+	for section, classmates := range s.classmates {
+		partnersNeeded := 0
+		for _, smallestGroups := range s.smallestGroups[current+1:] {
+			partnersNeeded += smallestGroups[section] - 1
+		}
+
+		for _, student := range classmates {
+			needed := partnersNeeded
+			if includeCurrent {
+				if group, grouped := groupOf[student]; grouped {
+					needed += group.DesiredSize - len(group.members)
+				} else {
+					needed += s.smallestGroups[current][section] - 1
+				}
+			}
+			if needed <= 0 {
+				continue
+			}
+
+			freshPartners := 0
+			for _, classmate := range classmates {
+				if classmate != student && !student.HasCollaboratedWith(classmate) {
+					freshPartners++
+				}
+			}
+			deficit += max(0, needed-freshPartners)
+		}
+	}
+	return (deficit + 1) / 2
+}
+
+// placementCost determines the fewest collaborations the student would repeat by joining any group in the project
+// that they can still join, and how many groups that is
+func placementCost(project *Project, student *Student) (int, int) {
+	cheapest, options := -1, 0
+	for _, group := range project.Groups {
+		if group.IsFull() || !project.CanJoin(group, student) {
+			continue
+		}
+		options++
+		cost := 0
+		for _, member := range group.members {
+			if member.HasCollaboratedWith(student) {
+				cost++
+			}
+		}
+		if cheapest < 0 || cost < cheapest {
+			cheapest = cost
+		}
+	}
+	return cheapest, options
+}
+
+// record copies the members of every group, as the best grouping found so far
+func (s *exactSearch) record() {
+	s.solution = nil
+	for _, project := range s.projects {
+		var groups [][]*Student
+		for _, group := range project.Groups {
+			groups = append(groups, append([]*Student{}, group.members...))
+		}
+		s.solution = append(s.solution, groups)
+	}
+}
+
+// restore places every student back into their group in the best grouping found, once the search is done
+func (s *exactSearch) restore() {
+	for i, project := range s.projects {
+		for j, group := range project.Groups {
+			for _, member := range s.solution[i][j] {
+				if !group.Contains(member) {
+					group.AddMember(member)
+					project.MarkStudentGrouped(member)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestExactFindsFewestRepairings(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		numStudents        int
+		groupSize          int
+		projects           []string
+		opts               []Option
+		expectedRepairings int
+	}{
+		{
+			name:               "two groups of three must repeat once in each group",
+			numStudents:        6,
+			groupSize:          3,
+			projects:           []string{"first", "second"},
+			expectedRepairings: 2,
+		},
+		{
+			name:               "nine students can be grouped four times without repeats",
+			numStudents:        9,
+			groupSize:          3,
+			projects:           []string{"first", "second", "third", "fourth"},
+			expectedRepairings: 0,
+		},
+		{
+			name:               "one more project than a repeat-free design allows",
+			numStudents:        9,
+			groupSize:          3,
+			projects:           []string{"first", "second", "third", "fourth", "fifth"},
+			expectedRepairings: 9,
+		},
+		{
+			name:               "the largest class the search allows",
+			numStudents:        MaxExactClassSize,
+			groupSize:          4,
+			projects:           []string{"first", "second", "third"},
+			expectedRepairings: 0,
+		},
+		{
+			name:        "students kept apart leave only one way to pair the class",
+			numStudents: 4,
+			groupSize:   2,
+			projects:    []string{"first", "second"},
+			opts: []Option{WithConstraints([]api.Constraint{
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s2@duke.edu"},
+			})},
+			expectedRepairings: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		roster := testRoster(testCase.numStudents)
+		generator := NewExactClassGrouping(testCase.groupSize, false, append(testCase.opts, WithSeed(1))...)
+		grouping, err := generator.GenerateContext(context.Background(), roster, testCase.projects, Budget{})
+		if err != nil {
+			t.Errorf("%s: expected a grouping, got error %v", testCase.name, err)
+			continue
+		}
+		checkEveryStudentGroupedOnce(t, testCase.name, roster, grouping)
+
+		if actual := grouping.Summary.Repairings; actual != testCase.expectedRepairings {
+			t.Errorf("%s: expected %d repairings, got %d", testCase.name, testCase.expectedRepairings, actual)
+		}
+		if !grouping.Summary.Optimal {
+			t.Errorf("%s: expected the grouping to be proven optimal", testCase.name)
+		}
+	}
+}
+
+func TestExactReportsBoundWhenStopped(t *testing.T) {
+	roster := testRoster(24)
+	generator := NewExactClassGrouping(4, false, WithSeed(1))
+	grouping, err := generator.GenerateContext(context.Background(), roster, []string{"first", "second", "third", "fourth", "fifth", "sixth", "seventh", "eighth"}, Budget{Timeout: 50 * time.Millisecond})
+	if _, ok := err.(*PartialGroupingError); !ok {
+		t.Fatalf("expected the search to be stopped, got %v", err)
+	}
+	if grouping.Summary.Optimal {
+		t.Errorf("expected a grouping that was not proven optimal")
+	}
+	if grouping.Summary.LowerBound > grouping.Summary.Repairings {
+		t.Errorf("expected the lower bound %d to be no more than the %d repairings found", grouping.Summary.LowerBound, grouping.Summary.Repairings)
+	}
+
+	if _, err := generator.GenerateContext(context.Background(), testRoster(MaxExactClassSize+1), []string{"first"}, Budget{}); err == nil {
+		t.Errorf("expected classes larger than %d students to be rejected", MaxExactClassSize)
+	}
+}
+
+func TestExactStopsWithoutATimeout(t *testing.T) {
+	// repeats can't be avoided for this class, and proving how few there can be takes minutes
+	generator := NewExactClassGrouping(3, false, WithSeed(1)).(*exactClassGrouping)
+	generator.defaultTimeout = 100 * time.Millisecond
+	grouping, err := generator.GenerateContext(context.Background(), testRoster(12), []string{"first", "second", "third", "fourth", "fifth"}, Budget{})
+	partial, ok := err.(*PartialGroupingError)
+	if !ok || !partial.Found() {
+		t.Fatalf("expected the search to stop at the default timeout with a grouping, got %v", err)
+	}
+	if grouping.Summary.LowerBound > grouping.Summary.Repairings {
+		t.Errorf("expected the lower bound %d to be no more than the %d repairings found", grouping.Summary.LowerBound, grouping.Summary.Repairings)
+	}
+}
+
+func TestExactReportsApartConflicts(t *testing.T) {
+	// students kept apart in a ring of five can't be split between two groups, although any two of them can
+	constraints := apartRing(5)
+	generator := NewExactClassGrouping(5, false, WithSeed(1), WithConstraints(constraints))
+	_, err := generator.GenerateContext(context.Background(), testRoster(10), []string{"design"}, Budget{})
+	expected := &ConstraintConflictError{Conflicts: []string{fmt.Sprintf("for design, no grouping follows rules %s", describeConstraints(constraints))}}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("correct conflicts not found:\n\twanted:\n\t%v\n\tgot:\n\t%v", expected, err)
+	}
+}
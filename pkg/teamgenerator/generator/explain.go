@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// explaining determines if what happens to students while grouping the project is being recorded
+func (p *Project) explaining() bool {
+	return p.decisions != nil
+}
+
+// explain records what happened to the student while grouping the project, if that is being recorded
+func (p *Project) explain(student *Student, format string, args ...interface{}) {
+	if p.explaining() {
+		p.decisions[student] = append(p.decisions[student], fmt.Sprintf(format, args...))
+	}
+}
+
+// groupNumber determines the number of the group in the project, counting from one
+func (p *Project) groupNumber(group *Group) int {
+	for i, other := range p.Groups {
+		if other == group {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// netIDs lists the NetIDs of the students
+func netIDs(students []*Student) string {
+	var ids []string
+	for _, student := range students {
+		ids = append(ids, student.NetID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// explanations describe how every student ended up in their group for every project: what happened to them while
+// the project was grouped, who they ended up with, and where they had worked with any of those students before,
+// in a prior grouping or an earlier project
+func explanations(projects []*Project) []api.Explanation {
+	var explanations []api.Explanation
+	for i, project := range projects {
+		for j, group := range project.Groups {
+			for _, member := range group.members {
+				narrative := append([]string{}, project.decisions[member]...)
+
+				var partners []*Student
+				for _, other := range group.members {
+					if other != member {
+						partners = append(partners, other)
+					}
+				}
+				if len(partners) == 0 {
+					narrative = append(narrative, fmt.Sprintf("ended up alone in group %d", j+1))
+				} else {
+					narrative = append(narrative, fmt.Sprintf("ended up in group %d with %s", j+1, netIDs(partners)))
+				}
+
+				for _, partner := range partners {
+					met := append([]string{}, member.priorGroupings[partner]...)
+					for _, earlier := range projects[:i] {
+						for _, earlierGroup := range earlier.Groups {
+							if earlierGroup.Contains(member) && earlierGroup.Contains(partner) {
+								met = append(met, earlier.Name)
+							}
+						}
+					}
+					if len(met) > 0 {
+						narrative = append(narrative, fmt.Sprintf("repeats a collaboration with %s from %s", partner.NetID, strings.Join(met, ", ")))
+					}
+				}
+
+				explanations = append(explanations, api.Explanation{Student: member.NetID, Project: project.Name, Group: j + 1, Narrative: narrative})
+			}
+		}
+	}
+	return explanations
+}
@@ -0,0 +1,121 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestExplanations(t *testing.T) {
+	roster := testRoster(9)
+	prior := testGrouping(roster, []int{0, 1, 2}, []int{3, 4, 5}, []int{6, 7, 8})
+	prior.Name = "warmup"
+	pinned := testGrouping(roster, []int{0, 1})
+	constraints := []api.Constraint{{Kind: api.MustBeTogether, Student: "s3@duke.edu", Partner: "s6@duke.edu"}}
+
+	var testCases = []struct {
+		name     string
+		strategy ClassGrouping
+	}{
+		{
+			name:     "reshuffling",
+			strategy: NewClassGrouping(3, false, WithSeed(1), WithExplanations(), WithPinnedGroups([]api.ProjectGrouping{pinned}), WithConstraints(constraints)),
+		},
+		{
+			name:     "annealing",
+			strategy: NewAnnealingClassGrouping(3, false, DefaultSchedule(), WithSeed(1), WithExplanations()),
+		},
+	}
+
+	for _, testCase := range testCases {
+		grouping := testCase.strategy.GenerateWithPriors(roster, []api.ProjectGrouping{prior}, []string{"design", "final"})
+
+		explained := map[string]api.Explanation{}
+		for _, explanation := range grouping.Summary.Explanations {
+			explained[explanation.Project+"/"+explanation.Student] = explanation
+		}
+		for _, project := range grouping.Projects {
+			for i, group := range project.Groups {
+				for _, member := range group.Members {
+					explanation, ok := explained[project.Name+"/"+member.NetID]
+					if !ok {
+						t.Errorf("%s: expected %s to be explained for %s", testCase.name, member.NetID, project.Name)
+						continue
+					}
+					if explanation.Group != i+1 {
+						t.Errorf("%s: expected %s to be explained in group %d for %s, got %d", testCase.name, member.NetID, i+1, project.Name, explanation.Group)
+					}
+					if !hasLine(explanation.Narrative, "ended up") {
+						t.Errorf("%s: expected %s to be told who they ended up with for %s, got %v", testCase.name, member.NetID, project.Name, explanation.Narrative)
+					}
+
+					for _, partner := range group.Members {
+						if partner.NetID == member.NetID {
+							continue
+						}
+						var met []string
+						if sameGroup(prior, member.NetID, partner.NetID) {
+							met = append(met, prior.Name)
+						}
+						if project.Name == "final" && sameGroup(grouping.Projects[0], member.NetID, partner.NetID) {
+							met = append(met, "design")
+						}
+						repeat := "repeats a collaboration with " + partner.NetID + " from " + strings.Join(met, ", ")
+						if explainsRepeat := hasLine(explanation.Narrative, repeat); explainsRepeat != (len(met) > 0) {
+							t.Errorf("%s: expected %s repeating with %s for %s to be explained as %q, got %v", testCase.name, member.NetID, partner.NetID, project.Name, repeat, explanation.Narrative)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	grouping := testCases[0].strategy.GenerateWithPriors(roster, []api.ProjectGrouping{prior}, []string{"design"})
+	for _, explanation := range grouping.Summary.Explanations {
+		switch explanation.Student {
+		case "s0@duke.edu", "s1@duke.edu":
+			if !hasLine(explanation.Narrative, "was pinned to group") {
+				t.Errorf("expected %s to be explained as pinned, got %v", explanation.Student, explanation.Narrative)
+			}
+		case "s3@duke.edu", "s6@duke.edu":
+			if !hasLine(explanation.Narrative, "was placed in group") {
+				t.Errorf("expected %s to be explained as kept together, got %v", explanation.Student, explanation.Narrative)
+			}
+		default:
+			if !hasLine(explanation.Narrative, "joined group") {
+				t.Errorf("expected %s to be explained as joining a group, got %v", explanation.Student, explanation.Narrative)
+			}
+		}
+	}
+
+	if unexplained := NewClassGrouping(3, false, WithSeed(1)).Generate(roster, []string{"design"}); len(unexplained.Summary.Explanations) > 0 {
+		t.Errorf("expected no explanations unless they are asked for, got %v", unexplained.Summary.Explanations)
+	}
+}
+
+// hasLine determines if any line of the narrative starts with the prefix
+func hasLine(narrative []string, prefix string) bool {
+	for _, line := range narrative {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameGroup determines if the students share a group in the project grouping
+func sameGroup(grouping api.ProjectGrouping, student, partner string) bool {
+	for _, group := range grouping.Groups {
+		found := 0
+		for _, member := range group.Members {
+			if member.NetID == student || member.NetID == partner {
+				found++
+			}
+		}
+		if found == 2 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package generator
+
+// NewMaxRepeatsScorer returns a scorer that finds the most repeated collaborations any one student has, so that
+// unavoidable repeats are not all given to the same students
+func NewMaxRepeatsScorer() Scorer {
+	return &maxRepeatsScorer{}
+}
+
+type maxRepeatsScorer struct{}
+
+// Score finds the largest number of repeated collaborations any student in the projects has
+func (s *maxRepeatsScorer) Score(projects []*Project) float64 {
+	most := 0
+	for _, repeats := range studentRepeats(projects) {
+		most = max(most, repeats)
+	}
+	return float64(most)
+}
+
+// NewRepeatVarianceScorer returns a scorer that measures how unevenly repeated collaborations are spread across
+// students
+func NewRepeatVarianceScorer() Scorer {
+	return &repeatVarianceScorer{}
+}
+
+type repeatVarianceScorer struct{}
+
+// Score determines the variance of the number of repeated collaborations every student in the projects has,
+// counting the students without any
+func (s *repeatVarianceScorer) Score(projects []*Project) float64 {
+	students := map[*Student]bool{}
+	for _, project := range projects {
+		for _, student := range project.UngroupedStudents {
+			students[student] = true
+		}
+		for _, group := range project.Groups {
+			for _, member := range group.Members() {
+				students[member] = true
+			}
+		}
+	}
+	if len(students) == 0 {
+		return 0
+	}
+
+	repeats := studentRepeats(projects)
+	var sum, sumOfSquares float64
+	for student := range students {
+		sum += float64(repeats[student])
+		sumOfSquares += float64(repeats[student] * repeats[student])
+	}
+	mean := sum / float64(len(students))
+	return sumOfSquares/float64(len(students)) - mean*mean
+}
+
+// studentRepeats counts the repeated collaborations every student has in the projects, leaving out those without
+// any. A repeated collaboration counts for both of the students in it.
+func studentRepeats(projects []*Project) map[*Student]int {
+	repeats := map[*Student]int{}
+	for pair, count := range pairCounts(projects) {
+		total := pair.student.Collaborations(pair.partner)
+		if repairings := max(0, total-1) - max(0, total-count-1); repairings > 0 {
+			repeats[pair.student] += repairings
+			repeats[pair.partner] += repairings
+		}
+	}
+	return repeats
+}
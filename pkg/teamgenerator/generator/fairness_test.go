@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFairnessScorers(t *testing.T) {
+	var testCases = []struct {
+		name             string
+		projects         [][][]int
+		expectedRepeats  map[string]int
+		expectedMost     float64
+		expectedVariance float64
+	}{
+		{
+			name:             "no repeats",
+			projects:         [][][]int{{{0, 1}, {2, 3}}, {{0, 2}, {1, 3}}},
+			expectedRepeats:  map[string]int{},
+			expectedMost:     0,
+			expectedVariance: 0,
+		},
+		{
+			name:             "repeats spread evenly",
+			projects:         [][][]int{{{0, 1}, {2, 3}}, {{0, 1}, {2, 3}}},
+			expectedRepeats:  map[string]int{"s0@duke.edu": 1, "s1@duke.edu": 1, "s2@duke.edu": 1, "s3@duke.edu": 1},
+			expectedMost:     1,
+			expectedVariance: 0,
+		},
+		{
+			name:             "repeats given to the same students",
+			projects:         [][][]int{{{0, 1}, {2, 3}}, {{0, 1}, {2}, {3}}, {{0, 1}, {2}, {3}}},
+			expectedRepeats:  map[string]int{"s0@duke.edu": 2, "s1@duke.edu": 2},
+			expectedMost:     2,
+			expectedVariance: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var roster []*Student
+		for _, student := range testRoster(4) {
+			roster = append(roster, NewStudent(student))
+		}
+		indexRoster(roster)
+		var projects []*Project
+		for _, groups := range testCase.projects {
+			projects = append(projects, testProject(roster, groups...))
+		}
+
+		actualRepeats := map[string]int{}
+		for student, repeats := range studentRepeats(projects) {
+			actualRepeats[student.NetID] = repeats
+		}
+		if expected := testCase.expectedRepeats; !reflect.DeepEqual(actualRepeats, expected) {
+			t.Errorf("%s: did not count repeats per student correctly, expected %v, got %v", testCase.name, expected, actualRepeats)
+		}
+		if actual, expected := NewMaxRepeatsScorer().Score(projects), testCase.expectedMost; actual != expected {
+			t.Errorf("%s: did not find the most repeats correctly, expected %g, got %g", testCase.name, expected, actual)
+		}
+		if actual, expected := NewRepeatVarianceScorer().Score(projects), testCase.expectedVariance; actual != expected {
+			t.Errorf("%s: did not determine the variance of repeats correctly, expected %g, got %g", testCase.name, expected, actual)
+		}
+	}
+}
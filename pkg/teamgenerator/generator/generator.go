@@ -1,9 +1,13 @@
 package generator
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
+	"os"
+	"time"
 
 	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
 )
@@ -12,148 +16,431 @@ const (
 	// maxReshuffles determines how many times a random student will be reshuffled in an attempt to move forward
 	// in fleshing out a project's groups without increasing the number of second collaborations
 	maxReshuffles = 1000
-)
 
-var (
-	// desiredRepairings is the maximum desired number of repairings to have been made when all projects have been
-	// fleshed out. This number will increase by 1 every time the algorithm tries and fails to complete the task
-	// for the given desired amount.
-	desiredRepairings = 0
+	// scoreRelaxation is the fraction of the best score found so far by which the acceptable score is relaxed
+	// every time an attempt doesn't reach it, so that the search settles faster for objectives with large scores
+	scoreRelaxation = 0.1
+
+	// scoredCandidates is the most students whose placement is scored on the objective when choosing who joins
+	// or is poached into a group, so that choosing stays cheap for large classes
+	scoredCandidates = 8
 
-	// netRepairings is the number of net repairings that have been created so far
-	// Collaborate and Decollaborate methods on Students change this counter
-	netRepairings = 0
+	// polishSwaps is the number of swaps between groups tried on every finished attempt to improve its score,
+	// as choosing students one at a time can't look ahead to the students left at the end
+	polishSwaps = 2000
 
-	// numReshuffles is the number of reshuffles that have been committed so far
-	numReshuffles = 0
+	// maxFailedAttempts is how many attempts in a row may fail for reasons that allowing more repairings can't fix
+	// before the search gives up, as the rules keeping students together or apart can rule out every grouping in
+	// ways that are too costly to check up front
+	maxFailedAttempts = 1000
 )
 
-func NewClassGrouping(optimalGroupSize int, preferSmallerGroups bool) ClassGrouping {
-	return &classGrouping{optimalGroupSize: optimalGroupSize, preferSmallerGroups: preferSmallerGroups}
+func NewClassGrouping(optimalGroupSize int, preferSmallerGroups bool, opts ...Option) ClassGrouping {
+	return &classGrouping{optimalGroupSize: optimalGroupSize, preferSmallerGroups: preferSmallerGroups, options: newOptions(opts)}
 }
 
 type classGrouping struct {
 	optimalGroupSize    int
 	preferSmallerGroups bool
+	options
+}
+
+// newRandom determines the seed for a new generation and creates the random source for it
+func (g *classGrouping) newRandom() (*rand.Rand, int64) {
+	seed := g.seed
+	if !g.seeded {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed)), seed
+}
+
+// sizingFor determines the sizes of groups for the project, which may override the defaults
+func (g *classGrouping) sizingFor(name string) GroupSizing {
+	sizing := GroupSizing{OptimalSize: g.optimalGroupSize, PreferSmallerGroups: g.preferSmallerGroups}
+	if size, ok := g.projectSizes[name]; ok {
+		sizing.OptimalSize, sizing.MinSize, sizing.MaxSize = size.OptimalSize, size.MinSize, size.MaxSize
+	}
+	return sizing
 }
 
 // Generate generates a class grouping from a roster
 func (g *classGrouping) Generate(students []api.Student, groupingNames []string) api.ClassGrouping {
-	for {
-		var roster []*Student
-		for _, student := range students {
-			roster = append(roster, NewStudent(student))
+	return g.GenerateWithPriors(students, nil, groupingNames)
+}
+
+// GenerateWithPriors generates a class grouping from a roster, taking into account prior groupings
+func (g *classGrouping) GenerateWithPriors(students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string) api.ClassGrouping {
+	grouping, _ := g.GenerateWithPriorsContext(context.Background(), students, priorGroupings, groupingNames, Budget{})
+	return grouping
+}
+
+// GenerateContext generates a class grouping from a roster, within the given budget
+func (g *classGrouping) GenerateContext(ctx context.Context, students []api.Student, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	return g.GenerateWithPriorsContext(ctx, students, nil, groupingNames, budget)
+}
+
+// GenerateWithPriorsContext generates a class grouping from a roster, taking into account prior groupings,
+// within the given budget
+func (g *classGrouping) GenerateWithPriorsContext(ctx context.Context, students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	if err := g.checkConstraints(students, groupingNames); err != nil {
+		return api.ClassGrouping{}, err
+	}
+
+	ctx, cancel := budget.apply(ctx)
+	defer cancel()
+
+	random, seed := g.newRandom()
+	generation := &generation{random: random}
+
+	best := newBestGrouping(seed, &g.options)
+
+	// failedAttempts counts the attempts in a row that failed for reasons allowing more repairings can't fix, and
+	// lastErr is why the last of them failed
+	failedAttempts := 0
+	var lastErr error
+
+	for attempts := 0; ; attempts++ {
+		if best.found && best.score <= generation.acceptableScore {
+			fmt.Printf("Succeeded at creating groupings with %d repairings\n", best.repairings())
+			return best.result(attempts, nil)
 		}
 
-		var projects []*Project
-		for _, name := range groupingNames {
-			projects = append(projects, NewProject(name, roster, g.optimalGroupSize, g.preferSmallerGroups))
+		if err := stopSearch(ctx, budget, attempts, best); err != nil {
+			return best.result(attempts, err)
+		}
+
+		if failedAttempts >= maxFailedAttempts {
+			reason := fmt.Sprintf("search gave up after %d attempts in a row failed, the last with: %v", failedAttempts, lastErr)
+			return best.result(attempts, &PartialGroupingError{Reason: reason, Attempts: attempts, Repairings: best.repairings(), Score: best.score})
+		}
+
+		roster, projects, err := g.newProjects(students, priorGroupings, groupingNames, random)
+		if err != nil {
+			// we could not place students that must be together in this attempt, but we may in the next
+			fmt.Fprintf(os.Stderr, "Failed to start an attempt at grouping: %v\n", err)
+			failedAttempts++
+			lastErr = err
+			continue
 		}
 
 		// we're starting a new attempt at pairing, so we reset the counters
-		netRepairings = 0
-		numReshuffles = 0
+		generation.netRepairings = 0
+		generation.numReshuffles = 0
+		generation.oddSizedGroups = map[*Student]int{}
+		generation.objective = nil
+		if g.hasSoftObjectives() {
+			generation.objective = func() float64 { return g.score(projects) }
+		}
 
-		for _, project := range projects {
-			if err := groupStudentsForProject(project, roster); err != nil {
-				// the only error that can occur in this step is the algorithm
-				// reaching the reshuffle quota limit. In that case, we need to
-				//  increase the number of desired repairings and try again
-				desiredRepairings++
-				fmt.Printf("Increased the amount of desired repairings to %d after reshuffle quota was reached\n", desiredRepairings)
+		if err := generation.groupStudentsForProjects(ctx, projects, roster); err != nil {
+			if ctx.Err() != nil {
+				// we were stopped in the middle of the attempt, so there is nothing more to do
+				continue
+			}
+			// the only other error that can occur in this step is the algorithm
+			// reaching the reshuffle quota limit. In that case, we need to
+			// increase the number of desired repairings and try again, and
+			// we can't expect as good a score from the groupings that allows.
+			// Once every collaboration could be a repairing, only the rules
+			// keeping students apart can be in the way.
+			if generation.desiredRepairings >= mostRepairings(projects) {
+				failedAttempts++
+				lastErr = err
 				continue
 			}
+			generation.desiredRepairings++
+			generation.relax(best)
+			fmt.Fprintf(os.Stderr, "Increased the amount of desired repairings to %d after reshuffle quota was reached\n", generation.desiredRepairings)
+			continue
 		}
+		failedAttempts = 0
 
-		if netRepairings <= desiredRepairings {
-			fmt.Printf("Succeeded at creating groupings with %d repairings\n", netRepairings)
-			var groupings []api.ProjectGrouping
-			for _, finishedProject := range projects {
-				groupings = append(groupings, finishedProject.ToAPIProjectGrouping())
-			}
+		if generation.objective != nil {
+			g.polish(projects, random)
+		}
+		score := g.score(projects)
+		best.consider(projects, score)
 
-			return api.ClassGrouping{Projects: groupings}
+		if score > generation.acceptableScore {
+			generation.relax(best)
+			fmt.Fprintf(os.Stderr, "Increased the acceptable score to %g after grouping succeeded with a score of %g\n", generation.acceptableScore, score)
 		}
-		desiredRepairings++
-		fmt.Printf("Increased the amount of desired repairings to %d after grouping succeeded with too many repairings\n", desiredRepairings)
 	}
 }
 
-// GenerateWithPriors generates a class grouping from a roster, taking into account prior groupings
-func (g *classGrouping) GenerateWithPriors(students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string) api.ClassGrouping {
-	for {
-		var roster []*Student
-		associativeRoster := map[string]*Student{}
-		for _, student := range students {
-			internalStudent := NewStudent(student)
-			roster = append(roster, internalStudent)
-			associativeRoster[student.NetID] = internalStudent
+// mostRepairings determines the number of repairings there would be if every collaboration in the projects was
+// one, which no grouping can have more of
+func mostRepairings(projects []*Project) int {
+	most := 0
+	for _, project := range projects {
+		for _, group := range project.Groups {
+			most += group.DesiredSize * (group.DesiredSize - 1) / 2
+		}
+	}
+	return most
+}
+
+// newProjects creates a fresh roster and set of projects for an attempt at grouping, with the
+// collaborations from the prior groupings already recorded and students that must be together
+// already placed in their groups
+func (g *classGrouping) newProjects(students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string, random *rand.Rand) ([]*Student, []*Project, error) {
+	roster, associativeRoster := g.newRoster(students, priorGroupings)
+
+	var projects []*Project
+	for _, name := range groupingNames {
+		project, err := g.newPinnedProject(name, roster, associativeRoster)
+		if err != nil {
+			return nil, nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	for _, project := range projects {
+		if err := g.placeConstraints(project, associativeRoster, random); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return roster, projects, nil
+}
+
+// newPinnedProject creates a project with its pinned groups in place and locked, sizing the other groups for the
+// rest of the roster
+func (g *classGrouping) newPinnedProject(name string, roster []*Student, associativeRoster map[string]*Student) (*Project, error) {
+	pinned := map[*Student]bool{}
+	var pinnedGroups []*Group
+	for _, pinnedGroup := range g.pinnedGroups[name] {
+		group := NewGroup(len(pinnedGroup.Members))
+		for _, member := range pinnedGroup.Members {
+			student := associativeRoster[member.NetID]
+			group.AddMember(student)
+			group.Section = student.Section
+			pinned[student] = true
 		}
+		pinnedGroups = append(pinnedGroups, group)
+	}
 
-		var projects []*Project
-		for _, name := range groupingNames {
-			projects = append(projects, NewProject(name, roster, g.optimalGroupSize, g.preferSmallerGroups))
+	var leftover []*Student
+	for _, student := range roster {
+		if !pinned[student] {
+			leftover = append(leftover, student)
 		}
+	}
+
+	project, err := NewProject(name, leftover, g.sizingFor(name))
+	if err != nil {
+		return nil, err
+	}
+	project.Groups = append(pinnedGroups, project.Groups...)
+	project.Roles = g.projectRoles[name]
+	for student := range pinned {
+		project.Lock(student)
+	}
+	if g.explain {
+		project.decisions = map[*Student][]string{}
+		for i, group := range pinnedGroups {
+			for _, member := range group.members {
+				project.explain(member, "was pinned to group %d", i+1)
+			}
+		}
+	}
+	return project, nil
+}
+
+// newRoster creates a fresh roster, along with a lookup of students by NetID, with the collaborations
+// from the prior groupings and their weights already recorded
+func (o *options) newRoster(students []api.Student, priorGroupings []api.ProjectGrouping) ([]*Student, map[string]*Student) {
+	var roster []*Student
+	associativeRoster := map[string]*Student{}
+	for _, student := range students {
+		internalStudent := NewStudent(student)
+		if free, ok := o.availability[student.NetID]; ok {
+			internalStudent.free = map[api.TimeSlot]bool{}
+			for _, slot := range free {
+				internalStudent.free[slot] = true
+			}
+		}
+		roster = append(roster, internalStudent)
+		associativeRoster[student.NetID] = internalStudent
+	}
+	indexRoster(roster)
+
+	// by creating a throwaway group for all of the groups that we're recieving as prior information,
+	// we can populate the collaboration lists
+	for i, prior := range priorGroupings {
+		weight := o.priorWeight(i, len(priorGroupings))
+		for _, group := range prior.Groups {
+			throwaway := NewGroup(len(group.Members))
+			for _, member := range group.Members {
+				if associativeRoster[member.NetID] != nil {
+					// if there's someone in our group that's not on the roster, we don't care about them
+					throwaway.AddMember(associativeRoster[member.NetID])
+					if len(member.Role) > 0 {
+						associativeRoster[member.NetID].priorRoles[member.Role]++
+					}
+				}
+			}
 
-		// by creating a throwaway group for all of the groups that we're recieving as prior information,
-		// we can populate the collaboration lists
-		for _, prior := range priorGroupings {
+			members := throwaway.Members()
+			for j := range members {
+				for _, partner := range members[j+1:] {
+					recordPriorWeight(members[j], partner, weight)
+					if o.explain {
+						recordPriorGrouping(members[j], partner, prior.Name)
+					}
+				}
+			}
+		}
+
+		var sizes []int
+		for _, group := range prior.Groups {
+			sizes = append(sizes, len(group.Members))
+		}
+		if usual, ok := usualSize(sizes); ok {
 			for _, group := range prior.Groups {
-				throwaway := NewGroup(len(group.Members))
+				if len(group.Members) == usual {
+					continue
+				}
 				for _, member := range group.Members {
-					if associativeRoster[member.NetID] != nil {
-						// if there's someone in our group that's not on the roster, we don't care about them
-						throwaway.AddMember(associativeRoster[member.NetID])
+					if student := associativeRoster[member.NetID]; student != nil {
+						student.priorOddSizedGroups++
 					}
 				}
 			}
 		}
+	}
 
-		// we're starting a new attempt at pairing, so we reset the counters
-		netRepairings = 0
-		numReshuffles = 0
+	return roster, associativeRoster
+}
+
+// generation holds the state of one call to generate a class grouping. No state is shared between generations,
+// so any number of them may run at once.
+type generation struct {
+	// random is the source of randomness for this generation
+	random *rand.Rand
+
+	// desiredRepairings is the maximum desired number of repairings to have been made when all projects have been
+	// fleshed out. This number will increase by 1 every time the algorithm tries and fails to complete the task
+	// for the given desired amount.
+	desiredRepairings int
+
+	// netRepairings is the number of net repairings that have been created so far in this attempt
+	netRepairings int
+
+	// acceptableScore is the highest score of a grouping that will be accepted without searching any further.
+	// It is kept apart from the desired repairings, as the objective may measure much more than repairings, and
+	// is relaxed every time an attempt fails or doesn't reach it.
+	acceptableScore float64
+
+	// objective scores the projects being grouped in this attempt, and is only set if the objective measures
+	// anything other than repairings. When set, it is used to choose between students who could join a group.
+	objective func() float64
+
+	// numReshuffles is the number of reshuffles that have been committed so far in this attempt
+	numReshuffles int
+
+	// oddSizedGroups are the number of odd-sized groups every student has been in for the projects
+	// grouped so far in this attempt
+	oddSizedGroups map[*Student]int
+
+	// fresh and stale hold the students who could join a group, and are reused for every student added so that
+	// large classes don't have to allocate them every time
+	fresh, stale []*Student
+}
+
+// polish improves the fully grouped projects on the objective by swapping students between groups, keeping every
+// swap that makes the score no worse without adding repairings or putting together students who must be apart
+func (g *classGrouping) polish(projects []*Project, random *rand.Rand) {
+	swappable := swappableGroups(projects)
+	score, repairings := g.score(projects), countRepairings(projects)
+	for i := 0; i < polishSwaps; i++ {
+		swap, ok := randomSwap(swappable, random)
+		if !ok {
+			return
+		}
+
+		swap.apply()
+		newScore, newRepairings := g.score(projects), countRepairings(projects)
+		if newScore > score || newRepairings > repairings || countSeparations(projects) > 0 {
+			swap.apply()
+			continue
+		}
+		score, repairings = newScore, newRepairings
 
 		for _, project := range projects {
-			if err := groupStudentsForProject(project, roster); err != nil {
-				// the only error that can occur in this step is the algorithm
-				// reaching the reshuffle quota limit. In that case, we need to
-				//  increase the number of desired repairings and try again
-				desiredRepairings++
-				fmt.Printf("Increased the amount of desired repairings to %d after reshuffle quota was reached\n", desiredRepairings)
-				continue
+			if project.explaining() && project.groupNumber(swap.group) > 0 {
+				from, to := project.groupNumber(swap.otherGroup), project.groupNumber(swap.group)
+				project.explain(swap.student, "was swapped from group %d to group %d with %s, as the swap scored no worse on the objective", from, to, swap.otherStudent.NetID)
+				project.explain(swap.otherStudent, "was swapped from group %d to group %d with %s, as the swap scored no worse on the objective", to, from, swap.student.NetID)
 			}
 		}
+	}
+}
 
-		if netRepairings <= desiredRepairings {
-			fmt.Printf("Succeeded at creating groupings with %d repairings\n", netRepairings)
-			var groupings []api.ProjectGrouping
-			for _, finishedProject := range projects {
-				groupings = append(groupings, finishedProject.ToAPIProjectGrouping())
-			}
+// relax raises the acceptable score by one, or by a fraction of the best score found so far if that is larger
+func (g *generation) relax(best *bestGrouping) {
+	step := 1.0
+	if best.found {
+		step = math.Max(step, scoreRelaxation*best.score)
+	}
+	g.acceptableScore += step
+}
+
+// addMember adds the student to the group and records any repairings that were created
+func (g *generation) addMember(group *Group, student *Student) {
+	g.netRepairings += group.AddMember(student)
+}
 
-			return api.ClassGrouping{Projects: groupings}
+// removeMember removes the student from the group and records any repairings that were undone
+func (g *generation) removeMember(group *Group, student *Student) {
+	g.netRepairings -= group.RemoveMember(student)
+}
+
+// groupStudentsForProjects will group students for every project in turn, stopping early if the context is done.
+// This method will return an error if the reshuffle quota is reached.
+func (g *generation) groupStudentsForProjects(ctx context.Context, projects []*Project, roster []*Student) error {
+	for _, project := range projects {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := g.groupStudentsForProject(project, roster); err != nil {
+			return err
+		}
+
+		for _, group := range project.Groups {
+			if project.IsOddSized(group) {
+				for _, member := range group.members {
+					g.oddSizedGroups[member]++
+				}
+			}
 		}
-		desiredRepairings++
-		fmt.Printf("Increased the amount of desired repairings to %d after grouping succeeded with too many repairings\n", desiredRepairings)
 	}
+	return nil
 }
 
 // groupStudentsForProject will assign groups members until all groups are fulfilled, while minimizing the number of times
 // any two students collaborate with each other.
 // This method will return an error if the reshuffle quota is reached.
-func groupStudentsForProject(project *Project, roster []*Student) error {
-	groupsToFill := &GroupQueue{}
-	for _, group := range project.Groups {
-		groupsToFill.Enqueue(group)
-	}
-
-	for {
-		if groupsToFill.IsEmpty() {
-			break
+func (g *generation) groupStudentsForProject(project *Project, roster []*Student) error {
+	// odd-sized groups are filled first, while there are still many students to choose from, so that
+	// their places can go to the students who have been in the fewest of them
+	for _, oddSized := range []bool{true, false} {
+		groupsToFill := &GroupQueue{}
+		for _, group := range project.Groups {
+			if !group.IsFull() && project.IsOddSized(group) == oddSized {
+				groupsToFill.Enqueue(group)
+			}
 		}
 
-		if err := addMemberToGroup(project, groupsToFill, roster); err != nil {
-			return err
+		for {
+			if groupsToFill.IsEmpty() {
+				break
+			}
+
+			if err := g.addMemberToGroup(project, groupsToFill, roster); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -163,7 +450,7 @@ func groupStudentsForProject(project *Project, roster []*Student) error {
 // addMemberToGroup adds a member to a group using the context of the given project and returns the number of
 // net repairings as a result of this action as well as the number of reshuffles used in this action
 // This method will return an error if the reshuffle quota is reached.
-func addMemberToGroup(project *Project, groupsToFill *GroupQueue, roster []*Student) error {
+func (g *generation) addMemberToGroup(project *Project, groupsToFill *GroupQueue, roster []*Student) error {
 	group := groupsToFill.Dequeue()
 	// we want to ensure that if we haven't filled this group with this addition, that the group ends up back on the
 	// queue of groups to fill
@@ -174,29 +461,63 @@ func addMemberToGroup(project *Project, groupsToFill *GroupQueue, roster []*Stud
 	}()
 
 	// ungrouped, fresh students are those that are ungrouped and have not collaborated with anyone in this group yet
-	ungroupedFreshStudents := []*Student{}
+	// ungrouped, stale students are those that are ungrouped and have collaborated with someone in this group
+	ungroupedFreshStudents, ungroupedStaleStudents := g.fresh[:0], g.stale[:0]
 	for _, unassignedStudent := range project.UngroupedStudents {
+		if !project.CanJoin(group, unassignedStudent) {
+			// no matter our quota, we can't put students in a group with someone they must be kept apart from
+			continue
+		}
 		if !group.ContainsCollaboratorsOf(unassignedStudent) {
 			ungroupedFreshStudents = append(ungroupedFreshStudents, unassignedStudent)
+		} else {
+			ungroupedStaleStudents = append(ungroupedStaleStudents, unassignedStudent)
 		}
 	}
+	g.fresh, g.stale = ungroupedFreshStudents, ungroupedStaleStudents
 
 	// TODO: make sure we're not doubling up on group members because we currently draw eligible students
 	// from the total roster
 
 	if len(ungroupedFreshStudents) != 0 {
-		// if we have ungrouped and fresh students, we can just add one to our group and move on
-		studentToAdd := ungroupedFreshStudents[rand.Intn(len(ungroupedFreshStudents))]
-		group.AddMember(studentToAdd)
+		// if we have ungrouped and fresh students, we can just add one to our group and move on, giving
+		// places in odd-sized groups to the students who have been in the fewest of them
+		pool := "who had not worked with anyone in it"
+		if project.IsOddSized(group) {
+			ungroupedFreshStudents = g.leastOddSized(ungroupedFreshStudents)
+			pool = "who had not worked with anyone in it and had been in the fewest groups of an unusual size"
+		}
+		studentToAdd := g.pickToJoin(project, group, ungroupedFreshStudents)
+		if project.explaining() {
+			switch {
+			case len(ungroupedFreshStudents) == 1:
+				project.explain(studentToAdd, "joined group %d as the only student left %s", project.groupNumber(group), pool)
+			case g.objective != nil:
+				project.explain(studentToAdd, "joined group %d as the best fit for the objective of up to %d students picked at random from the %d left %s", project.groupNumber(group), scoredCandidates, len(ungroupedFreshStudents), pool)
+			default:
+				project.explain(studentToAdd, "joined group %d, picked at random from the %d students left %s", project.groupNumber(group), len(ungroupedFreshStudents), pool)
+			}
+		}
+		g.addMember(group, studentToAdd)
 		project.MarkStudentGrouped(studentToAdd)
 		return nil
 	}
 
-	if netRepairings < desiredRepairings {
+	if g.netRepairings < g.desiredRepairings && len(ungroupedStaleStudents) != 0 {
 		// if we don't have any ungrouped and fresh students to add to this group but we still have some of our repairing
-		// quota left, we can simply add an ungrouped but stale student to our group
-		studentToAdd := project.UngroupedStudents[rand.Intn(len(project.UngroupedStudents))]
-		group.AddMember(studentToAdd)
+		// quota left, we can simply add an ungrouped but stale student to our group, as long as it's one whose
+		// repeated collaborations cost the least
+		studentToAdd := g.pickToJoin(project, group, g.cheapestStudentsFor(group, ungroupedStaleStudents))
+		if project.explaining() {
+			var collaborators []*Student
+			for _, member := range group.members {
+				if member.HasCollaboratedWith(studentToAdd) {
+					collaborators = append(collaborators, member)
+				}
+			}
+			project.explain(studentToAdd, "joined group %d although they had worked with %s before, as everyone left who could join it had worked with someone in it and repeating their collaborations cost the least", project.groupNumber(group), netIDs(collaborators))
+		}
+		g.addMember(group, studentToAdd)
 		project.MarkStudentGrouped(studentToAdd)
 		return nil
 	}
@@ -204,25 +525,20 @@ func addMemberToGroup(project *Project, groupsToFill *GroupQueue, roster []*Stud
 	// if we don't have any repairing quota to use, we're going to need to undo some previously-assigned grouping
 	// randomly in the hopes of moving out of this predicament. This is called a reshuffle and we limit the number
 	// of times we let this occur
-	numReshuffles++
-	if numReshuffles >= maxReshuffles {
+	g.numReshuffles++
+	if g.numReshuffles >= maxReshuffles {
 		return errors.New("ran out of reshuffle quota")
 	}
 
 	// first, we check to see if there are any grouped students in the class that could possibly go in this group
 	// without increasing the total number of re-pairings
-	potentialStudents := []*Student{}
-	for _, student := range roster {
-		if !group.ContainsCollaboratorsOf(student) {
-			potentialStudents = append(potentialStudents, student)
-		}
-	}
+	potentialStudents := potentialStudentsFor(project, group, roster)
 
 	if len(potentialStudents) != 0 {
 		// there are members of the class that could belong to this group, but belong to other groups instead.
 		// we're going to remove one of them from their current group, put them into ours
-		studentToPoach := potentialStudents[rand.Intn(len(potentialStudents))]
-		poachStudentIntoGroup(studentToPoach, group, project, groupsToFill)
+		studentToPoach := g.pickToPoach(project, group, potentialStudents)
+		g.poachStudentIntoGroup(studentToPoach, group, project, groupsToFill)
 		return nil
 	}
 
@@ -234,35 +550,167 @@ func addMemberToGroup(project *Project, groupsToFill *GroupQueue, roster []*Stud
 			break
 		}
 
-		unluckyStudent := group.members[rand.Intn(len(group.members))]
-		group.RemoveMember(unluckyStudent)
-		project.MarkStudentUngrouped(unluckyStudent)
-
-		for _, student := range roster {
-			if !group.ContainsCollaboratorsOf(student) {
-				potentialStudents = append(potentialStudents, student)
+		// students that are locked into this group can't be the unlucky ones
+		unlockedMembers := []*Student{}
+		for _, member := range group.members {
+			if !project.IsLocked(member) {
+				unlockedMembers = append(unlockedMembers, member)
 			}
 		}
+		if len(unlockedMembers) == 0 {
+			return errors.New("no students can be removed from a group that nobody else can join")
+		}
+
+		unluckyStudent := unlockedMembers[g.random.Intn(len(unlockedMembers))]
+		if project.explaining() {
+			project.explain(unluckyStudent, "was removed from group %d at random to make room, as nobody in the class could join it without repeating a collaboration", project.groupNumber(group))
+		}
+		g.removeMember(group, unluckyStudent)
+		project.MarkStudentUngrouped(unluckyStudent)
+
+		potentialStudents = potentialStudentsFor(project, group, roster)
 	}
 
 	// we've removed enough members from the group so that someone else in the class can fit in this group
-	studentToPoach := potentialStudents[rand.Intn(len(potentialStudents))]
-	poachStudentIntoGroup(studentToPoach, group, project, groupsToFill)
+	studentToPoach := g.pickToPoach(project, group, potentialStudents)
+	g.poachStudentIntoGroup(studentToPoach, group, project, groupsToFill)
 	return nil
 }
 
+// leastOddSized finds the students who have been in the fewest odd-sized groups, in prior groupings
+// and in this attempt
+func (g *generation) leastOddSized(students []*Student) []*Student {
+	var least []*Student
+	fewest := -1
+	for _, student := range students {
+		count := student.priorOddSizedGroups + g.oddSizedGroups[student]
+		switch {
+		case fewest < 0 || count < fewest:
+			least, fewest = []*Student{student}, count
+		case count == fewest:
+			least = append(least, student)
+		}
+	}
+	return least
+}
+
+// cheapestStudentsFor finds the students whose repeated collaborations with the members of the group cost the
+// least, as collaborations from prior groupings may be weighted
+func (g *generation) cheapestStudentsFor(group *Group, students []*Student) []*Student {
+	var cheapest []*Student
+	lowestCost := math.Inf(1)
+	for _, student := range students {
+		var cost float64
+		for _, member := range group.members {
+			if member.HasCollaboratedWith(student) {
+				cost += member.PriorWeight(student)
+			}
+		}
+		switch {
+		case cost < lowestCost:
+			cheapest, lowestCost = []*Student{student}, cost
+		case cost == lowestCost:
+			cheapest = append(cheapest, student)
+		}
+	}
+	return cheapest
+}
+
+// pickToJoin chooses which of the ungrouped students joins the group: at random, or, if the objective measures
+// anything other than repairings, the one whose joining scores best of a random few
+func (g *generation) pickToJoin(project *Project, group *Group, students []*Student) *Student {
+	return g.pick(students, func(student *Student) float64 {
+		return g.joinScore(project, group, student)
+	})
+}
+
+// pickToPoach chooses which of the students is poached into the group: at random, or, if the objective measures
+// anything other than repairings, the one whose move scores best of a random few
+func (g *generation) pickToPoach(project *Project, group *Group, students []*Student) *Student {
+	return g.pick(students, func(student *Student) float64 {
+		var from *Group
+		for _, other := range project.Groups {
+			if other.Contains(student) {
+				from = other
+			}
+		}
+		if from == nil {
+			return g.joinScore(project, group, student)
+		}
+		from.RemoveMember(student)
+		group.AddMember(student)
+		score := g.objective()
+		group.RemoveMember(student)
+		from.AddMember(student)
+		return score
+	})
+}
+
+// joinScore scores the objective with the ungrouped student in the group
+func (g *generation) joinScore(project *Project, group *Group, student *Student) float64 {
+	group.AddMember(student)
+	project.MarkStudentGrouped(student)
+	score := g.objective()
+	group.RemoveMember(student)
+	project.MarkStudentUngrouped(student)
+	return score
+}
+
+// pick chooses one of the students at random or, if there is an objective, the one that scores best of up to
+// scoredCandidates of them chosen at random, breaking ties at random
+func (g *generation) pick(students []*Student, score func(*Student) float64) *Student {
+	if g.objective == nil || len(students) == 1 {
+		return students[g.random.Intn(len(students))]
+	}
+
+	candidates := students
+	if len(candidates) > scoredCandidates {
+		candidates = make([]*Student, scoredCandidates)
+		for i, j := range g.random.Perm(len(students))[:scoredCandidates] {
+			candidates[i] = students[j]
+		}
+	}
+
+	var best []*Student
+	lowest := math.Inf(1)
+	for _, candidate := range candidates {
+		switch value := score(candidate); {
+		case value < lowest:
+			best, lowest = []*Student{candidate}, value
+		case value == lowest:
+			best = append(best, candidate)
+		}
+	}
+	return best[g.random.Intn(len(best))]
+}
+
+// potentialStudentsFor determines which students in the class could join the group without increasing the
+// number of re-pairings, if they were moved out of their current group
+func potentialStudentsFor(project *Project, group *Group, roster []*Student) []*Student {
+	potentialStudents := []*Student{}
+	for _, student := range roster {
+		if !group.ContainsCollaboratorsOf(student) && !project.IsLocked(student) && project.CanJoin(group, student) {
+			potentialStudents = append(potentialStudents, student)
+		}
+	}
+	return potentialStudents
+}
+
 // poachStudentIntoGroup removes the student to poach from their current group and adds them to the group needing a member
-func poachStudentIntoGroup(studentToPoach *Student, groupNeedingMember *Group, project *Project, groupsToFill *GroupQueue) {
+func (g *generation) poachStudentIntoGroup(studentToPoach *Student, groupNeedingMember *Group, project *Project, groupsToFill *GroupQueue) {
 	previouslyGrouped := false
 	for _, unluckyGroup := range project.Groups {
 		if unluckyGroup.Contains(studentToPoach) {
 			previouslyGrouped = true
+			if project.explaining() {
+				project.explain(studentToPoach, "was moved from group %d to group %d, where they had not worked with anyone, as nobody left could join it without repeating a collaboration", project.groupNumber(unluckyGroup), project.groupNumber(groupNeedingMember))
+			}
 			if unluckyGroup.IsFull() {
 				// we want to enqueue only if the group is full, as in that case we know it's not in the queue
 				// if the group isn't full yet, the group is already in the queue and we don't need to add it
 				groupsToFill.Enqueue(unluckyGroup)
 			}
-			unluckyGroup.RemoveMember(studentToPoach)
+			g.removeMember(unluckyGroup, studentToPoach)
 		}
 	}
 
@@ -270,9 +718,12 @@ func poachStudentIntoGroup(studentToPoach *Student, groupNeedingMember *Group, p
 		// if someone asks us to poach a student that hasn't been grouped yet, we can still "poach" them but we need to
 		// do the bookkeeping ourselves to mark them as having been grouped
 		project.MarkStudentGrouped(studentToPoach)
+		if project.explaining() {
+			project.explain(studentToPoach, "joined group %d, where they had not worked with anyone, after students were removed from it to make room", project.groupNumber(groupNeedingMember))
+		}
 	}
 
-	groupNeedingMember.AddMember(studentToPoach)
+	g.addMember(groupNeedingMember, studentToPoach)
 	if !groupNeedingMember.IsFull() {
 		groupsToFill.Enqueue(groupNeedingMember)
 	}
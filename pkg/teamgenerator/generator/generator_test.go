@@ -0,0 +1,239 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// testRoster creates a roster with the given number of students
+func testRoster(numStudents int) []api.Student {
+	var roster []api.Student
+	for i := 0; i < numStudents; i++ {
+		roster = append(roster, api.Student{FullName: fmt.Sprintf("Student %d", i), NetID: fmt.Sprintf("s%d@duke.edu", i)})
+	}
+	return roster
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	roster := testRoster(20)
+	projects := []string{"first", "second", "third"}
+	generator := NewClassGrouping(3, false, WithSeed(42))
+
+	expected := generator.Generate(roster, projects)
+	if expected.Seed != 42 {
+		t.Fatalf("did not record the seed in the grouping, expected %d, got %d", 42, expected.Seed)
+	}
+
+	var wg sync.WaitGroup
+	actuals := make([]api.ClassGrouping, 4)
+	for i := range actuals {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			actuals[i] = generator.Generate(roster, projects)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, actual := range actuals {
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("generation %d with the same seed did not create the same grouping:\n\texpected:\n\t%v\n\tgot:\n\t%v", i, expected, actual)
+		}
+	}
+}
+
+func TestGenerateContextRespectsBudget(t *testing.T) {
+	roster := testRoster(12)
+	var projects []string
+	for i := 0; i < 10; i++ {
+		projects = append(projects, fmt.Sprintf("project %d", i))
+	}
+	generator := NewClassGrouping(3, false, WithSeed(42))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var testCases = []struct {
+		name               string
+		ctx                context.Context
+		budget             Budget
+		expectedFound      bool
+		expectedAttempts   int
+		expectedCancelled  bool
+		expectedProjectLen int
+	}{
+		{
+			name:               "attempt budget spent before any grouping is complete",
+			ctx:                context.Background(),
+			budget:             Budget{Attempts: 1},
+			expectedFound:      false,
+			expectedAttempts:   1,
+			expectedProjectLen: 0,
+		},
+		{
+			name:               "context cancelled before any attempt",
+			ctx:                cancelled,
+			budget:             Budget{},
+			expectedFound:      false,
+			expectedAttempts:   0,
+			expectedCancelled:  true,
+			expectedProjectLen: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		grouping, err := generator.GenerateContext(testCase.ctx, roster, projects, testCase.budget)
+		partial, ok := err.(*PartialGroupingError)
+		if !ok {
+			t.Errorf("%s: expected a partial grouping error, got %v", testCase.name, err)
+			continue
+		}
+
+		if actual, expected := partial.Found(), testCase.expectedFound; actual != expected {
+			t.Errorf("%s: expected found to be %v, got %v", testCase.name, expected, actual)
+		}
+		if actual, expected := partial.Attempts, testCase.expectedAttempts; actual != expected {
+			t.Errorf("%s: expected %d attempts, got %d", testCase.name, expected, actual)
+		}
+		if actual, expected := errors.Is(err, context.Canceled), testCase.expectedCancelled; actual != expected {
+			t.Errorf("%s: expected cancellation to be %v, got %v", testCase.name, expected, actual)
+		}
+		if actual, expected := len(grouping.Projects), testCase.expectedProjectLen; actual != expected {
+			t.Errorf("%s: expected %d projects in the best grouping, got %d", testCase.name, expected, actual)
+		}
+		if !grouping.Summary.Partial {
+			t.Errorf("%s: expected the summary to record a partial grouping", testCase.name)
+		}
+	}
+}
+
+func TestGenerateGivesUpOnRulesNoGroupingFollows(t *testing.T) {
+	// students kept apart in a ring of five can't be split between two groups, although any two of them can, so
+	// the rules pass the checks made up front and no budget stops the search
+	generator := NewClassGrouping(5, false, WithSeed(1), WithConstraints(apartRing(5)))
+	grouping, err := generator.GenerateContext(context.Background(), testRoster(10), []string{"design"}, Budget{})
+	partial, ok := err.(*PartialGroupingError)
+	if !ok {
+		t.Fatalf("expected a partial grouping error, got %v", err)
+	}
+	if partial.Found() || len(grouping.Projects) > 0 {
+		t.Errorf("expected no grouping to be found, got %v", grouping.Projects)
+	}
+}
+
+// sectionedRoster creates a roster with the given number of students in each section
+func sectionedRoster(sectionSizes map[string]int) []api.Student {
+	var roster []api.Student
+	for _, section := range []string{"01", "02", "03"} {
+		for i := 0; i < sectionSizes[section]; i++ {
+			roster = append(roster, api.Student{FullName: fmt.Sprintf("Student %s-%d", section, i), NetID: fmt.Sprintf("s%s%d@duke.edu", section, i), Section: section})
+		}
+	}
+	return roster
+}
+
+func TestGenerateKeepsSections(t *testing.T) {
+	roster := sectionedRoster(map[string]int{"01": 10, "02": 7})
+	sectionOf := map[string]string{}
+	for _, student := range roster {
+		sectionOf[student.NetID] = student.Section
+	}
+
+	var testCases = []struct {
+		name      string
+		generator ClassGrouping
+		budget    Budget
+	}{
+		{
+			name:      "reshuffle",
+			generator: NewClassGrouping(3, false, WithSeed(3)),
+		},
+		{
+			name:      "anneal",
+			generator: NewAnnealingClassGrouping(3, false, DefaultSchedule(), WithSeed(3)),
+			budget:    Budget{Attempts: 1},
+		},
+		{
+			name:      "design",
+			generator: NewDesignClassGrouping(3, false, NewClassGrouping(3, false, WithSeed(3)), WithSeed(3)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		grouping, err := testCase.generator.GenerateContext(context.Background(), roster, []string{"first", "second"}, testCase.budget)
+		if !foundGrouping(grouping, err) {
+			t.Errorf("%s: expected a grouping, got error %v", testCase.name, err)
+			continue
+		}
+		checkEveryStudentGroupedOnce(t, testCase.name, roster, grouping)
+
+		for _, project := range grouping.Projects {
+			groupsInSection := map[string]int{}
+			for _, group := range project.Groups {
+				groupsInSection[group.Section]++
+				for _, member := range group.Members {
+					if sectionOf[member.NetID] != group.Section {
+						t.Errorf("%s: %s from section %q was grouped in section %q for %s", testCase.name, member.NetID, sectionOf[member.NetID], group.Section, project.Name)
+					}
+				}
+			}
+			if expected := map[string]int{"01": 3, "02": 2}; !reflect.DeepEqual(groupsInSection, expected) {
+				t.Errorf("%s: expected groups in every section to be %v for %s, got %v", testCase.name, expected, project.Name, groupsInSection)
+			}
+		}
+	}
+}
+
+func TestGenerateUsesProjectSizes(t *testing.T) {
+	roster := testRoster(12)
+	sizes := []api.ProjectSize{{Name: "lab", OptimalSize: 2}, {Name: "final", MinSize: 5, MaxSize: 6}}
+	generator := NewClassGrouping(3, false, WithSeed(7), WithProjectSizes(sizes))
+
+	grouping, err := generator.GenerateContext(context.Background(), roster, []string{"lab", "design", "final"}, Budget{})
+	if err != nil {
+		t.Fatalf("expected a grouping, got error %v", err)
+	}
+	checkEveryStudentGroupedOnce(t, "project sizes", roster, grouping)
+
+	expected := map[string][]int{"lab": {2, 2, 2, 2, 2, 2}, "design": {3, 3, 3, 3}, "final": {6, 6}}
+	for _, project := range grouping.Projects {
+		var actual []int
+		for _, group := range project.Groups {
+			actual = append(actual, len(group.Members))
+		}
+		if !reflect.DeepEqual(actual, expected[project.Name]) {
+			t.Errorf("expected groups of sizes %v for %s, got %v", expected[project.Name], project.Name, actual)
+		}
+	}
+
+	_, err = generator.GenerateContext(context.Background(), testRoster(9), []string{"final"}, Budget{})
+	if _, ok := err.(*ConstraintConflictError); !ok {
+		t.Errorf("expected sizes that cannot fit the roster to conflict, got %v", err)
+	}
+}
+
+func TestGenerateOptimizesSoftObjectives(t *testing.T) {
+	// the years can be spread so that nobody is the only one of their year in their group for either project
+	roster := testRoster(16)
+	for i := range roster {
+		roster[i].Attributes = map[string]string{"Year": fmt.Sprintf("%d", i%4)}
+	}
+
+	for _, seed := range []int64{1, 2, 3} {
+		generator := NewClassGrouping(4, false, WithSeed(seed), WithNoIsolation("Year", "", 1))
+		grouping, err := generator.GenerateContext(context.Background(), roster, []string{"design", "final"}, Budget{})
+		if err != nil {
+			t.Fatalf("seed %d: expected a grouping, got error %v", seed, err)
+		}
+		checkEveryStudentGroupedOnce(t, "soft objectives", roster, grouping)
+		if grouping.Summary.Score != 0 {
+			t.Errorf("seed %d: expected a grouping without repairings or isolated students, got a score of %g with %d repairings", seed, grouping.Summary.Score, grouping.Summary.Repairings)
+		}
+	}
+}
@@ -13,6 +13,12 @@ type Group struct {
 
 	// DesiredSize is the number of students the group should have once populated
 	DesiredSize int
+
+	// Section is the section of the class whose students may join the group
+	Section string
+
+	// roles are the roles assigned to members once the group is populated
+	roles map[*Student]string
 }
 
 // NewGroup creates a new group with the given desired size
@@ -24,17 +30,23 @@ func NewGroup(desiredSize int) *Group {
 func (g *Group) ToAPIGroup() api.Group {
 	var members []api.Student
 	for _, member := range g.members {
-		members = append(members, member.ToAPIStudent())
+		apiMember := member.ToAPIStudent()
+		apiMember.Role = g.roles[member]
+		members = append(members, apiMember)
 	}
 
-	return api.Group{Members: members}
+	group := api.Group{Members: members, Section: g.Section}
+	if shared, known := sharedHours(g.members); known {
+		group.Meetings = meetingWindows(shared)
+	}
+	return group
 }
 
-// Contains determines if the group contains the given student. Students are assumed to be
-// uniquely identifiable by their NetID
+// Contains determines if the group contains the given student. Students are identified by their
+// index in the class
 func (g *Group) Contains(student *Student) bool {
 	for _, member := range g.members {
-		if member.Equals(student) {
+		if member.index == student.index {
 			return true
 		}
 	}
@@ -54,34 +66,48 @@ func (g *Group) ContainsCollaboratorsOf(student *Student) bool {
 	return false
 }
 
-// AddMember adds the member to the group and updates all members' collaborator lists
-// and appropriately delegates changes to the repairing counter if any need to be made
-func (g *Group) AddMember(student *Student) {
+// AddMember adds the member to the group and updates all members' collaborator lists,
+// returning the number of repairings that were created by adding the member
+func (g *Group) AddMember(student *Student) int {
 	if g.Contains(student) {
 		fmt.Printf("adding member to group twice!!")
 	}
 
+	repairings := 0
 	for _, currentMember := range g.members {
-		Collaborate(currentMember, student)
+		if Collaborate(currentMember, student) {
+			repairings++
+		}
 	}
 
 	g.members = append(g.members, student)
+	return repairings
 }
 
-// RemoveMember removes the member to the group and updates all members' collaborator lists
-// and appropriately delegates changes to the repairing counter if any need to be made
-func (g *Group) RemoveMember(student *Student) {
+// RemoveMember removes the member to the group and updates all members' collaborator lists,
+// returning the number of repairings that were undone by removing the member
+func (g *Group) RemoveMember(student *Student) int {
+	repairings := 0
 	removeIndex := -1
 	for i, currentMember := range g.members {
-		Uncollaborate(currentMember, student)
-		if currentMember.Equals(student) {
+		if currentMember.index == student.index {
 			removeIndex = i
+			continue
+		}
+		if Uncollaborate(currentMember, student) {
+			repairings++
 		}
 	}
 
-	if removeIndex > 0 {
-		g.members = append(g.members[:removeIndex], g.members[removeIndex:]...)
+	if removeIndex >= 0 {
+		g.members = append(g.members[:removeIndex], g.members[removeIndex+1:]...)
 	}
+	return repairings
+}
+
+// Members returns the students that make up the group
+func (g *Group) Members() []*Student {
+	return g.members
 }
 
 // IsFull determines if the group has enough members
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRemoveMember(t *testing.T) {
+	var testCases = []struct {
+		name                   string
+		remove                 int
+		groupSize              int
+		expectedMembers        []string
+		expectedRepairings     int
+		expectedCollaborations [][]int
+	}{
+		{
+			name:                   "first member",
+			remove:                 0,
+			expectedMembers:        []string{"s1@duke.edu", "s2@duke.edu"},
+			expectedRepairings:     1,
+			expectedCollaborations: [][]int{{0, 1, 0, 0}, {1, 0, 1, 0}, {0, 1, 0, 0}, {0, 0, 0, 0}},
+		},
+		{
+			name:                   "middle member",
+			remove:                 1,
+			expectedMembers:        []string{"s0@duke.edu", "s2@duke.edu"},
+			expectedRepairings:     1,
+			expectedCollaborations: [][]int{{0, 1, 1, 0}, {1, 0, 0, 0}, {1, 0, 0, 0}, {0, 0, 0, 0}},
+		},
+		{
+			name:                   "last member",
+			remove:                 2,
+			expectedMembers:        []string{"s0@duke.edu", "s1@duke.edu"},
+			expectedRepairings:     0,
+			expectedCollaborations: [][]int{{0, 2, 0, 0}, {2, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}},
+		},
+		{
+			name:                   "only member",
+			remove:                 0,
+			groupSize:              1,
+			expectedMembers:        nil,
+			expectedRepairings:     0,
+			expectedCollaborations: [][]int{{0, 1, 0, 0}, {1, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}},
+		},
+		{
+			name:                   "student not in the group",
+			remove:                 3,
+			expectedMembers:        []string{"s0@duke.edu", "s1@duke.edu", "s2@duke.edu"},
+			expectedRepairings:     0,
+			expectedCollaborations: [][]int{{0, 2, 1, 0}, {2, 0, 1, 0}, {1, 1, 0, 0}, {0, 0, 0, 0}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		var roster []*Student
+		for _, student := range testRoster(4) {
+			roster = append(roster, NewStudent(student))
+		}
+		indexRoster(roster)
+		// the first two students worked together before, so the group repeats their collaboration
+		Collaborate(roster[0], roster[1])
+		groupSize := testCase.groupSize
+		if groupSize == 0 {
+			groupSize = 3
+		}
+		group := NewGroup(groupSize)
+		for _, member := range roster[:groupSize] {
+			group.AddMember(member)
+		}
+
+		if actual, expected := group.RemoveMember(roster[testCase.remove]), testCase.expectedRepairings; actual != expected {
+			t.Errorf("%s: expected %d repairings to be undone, got %d", testCase.name, expected, actual)
+		}
+		var members []string
+		for _, member := range group.Members() {
+			members = append(members, member.NetID)
+		}
+		if !reflect.DeepEqual(members, testCase.expectedMembers) {
+			t.Errorf("%s: expected members %v, got %v", testCase.name, testCase.expectedMembers, members)
+		}
+		var collaborations [][]int
+		for _, student := range roster {
+			var row []int
+			for _, partner := range roster {
+				row = append(row, student.Collaborations(partner))
+			}
+			collaborations = append(collaborations, row)
+		}
+		if !reflect.DeepEqual(collaborations, testCase.expectedCollaborations) {
+			t.Errorf("%s: expected collaborations %v, got %v", testCase.name, testCase.expectedCollaborations, collaborations)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package generator
 
-import "github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+import (
+	"context"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
 
 // ClassGrouping knows how to generate a class grouping from a roster
 type ClassGrouping interface {
@@ -9,4 +13,36 @@ type ClassGrouping interface {
 
 	// GenerateWithPriors generates a class grouping from a roster, taking into account prior groupings
 	GenerateWithPriors(students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string) (grouping api.ClassGrouping)
+
+	// GenerateContext generates a class grouping from a roster, searching until the context is done or the
+	// budget is spent. If the search is stopped early, the best grouping found so far is returned along with
+	// a *PartialGroupingError.
+	GenerateContext(ctx context.Context, students []api.Student, groupingNames []string, budget Budget) (grouping api.ClassGrouping, err error)
+
+	// GenerateWithPriorsContext generates a class grouping from a roster, taking into account prior groupings,
+	// searching until the context is done or the budget is spent. If the search is stopped early, the best
+	// grouping found so far is returned along with a *PartialGroupingError.
+	GenerateWithPriorsContext(ctx context.Context, students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string, budget Budget) (grouping api.ClassGrouping, err error)
+}
+
+// Scorer knows how to score a set of projects, whether or not all of their groups have been filled.
+// Lower scores are better.
+type Scorer interface {
+	// Score scores the groups in the projects
+	Score(projects []*Project) float64
+}
+
+// Repairer knows how to repair a grouping after students join or drop the class
+type Repairer interface {
+	// Repair moves as few students as possible to restore valid group sizes to the existing grouping for the
+	// new roster, avoiding repairings with the prior groupings where it can. The repaired grouping is returned
+	// along with the moves that were made and the groups that were given new numbers.
+	Repair(existing api.ProjectGrouping, students []api.Student, priorGroupings []api.ProjectGrouping) (grouping api.ProjectGrouping, moves []Move, renumbered []Renumbering, err error)
+}
+
+// Validator knows how to check a grouping for mistakes
+type Validator interface {
+	// Validate checks the class grouping against the roster and prior groupings, returning every way in which it
+	// breaks the rules that generated groupings follow
+	Validate(students []api.Student, grouping api.ClassGrouping, priorGroupings []api.ProjectGrouping) (violations []Violation)
 }
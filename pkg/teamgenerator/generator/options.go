@@ -0,0 +1,296 @@
+package generator
+
+import (
+	"math"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// Option configures how a ClassGrouping generates groups
+type Option func(*options)
+
+// options holds the configuration shared by all generations made by a ClassGrouping
+type options struct {
+	// seed is the seed for the random source used for every generation, if seeded is set
+	seed int64
+
+	// seeded determines if seed was set explicitly, otherwise a new seed is chosen for every generation
+	seeded bool
+
+	// scorers make up the objective that is minimized by the search for a grouping
+	scorers []weightedScorer
+
+	// additionalScorers are added to the objective on top of the scorers, without replacing the default
+	additionalScorers []weightedScorer
+
+	// constraints are hard rules about which students may share groups
+	constraints []api.Constraint
+
+	// preferences are who students would like to work with or not, reported on in the summary
+	preferences []api.Preference
+
+	// priorWeights are how much repeating a collaboration from every prior grouping costs, in order
+	priorWeights []float64
+
+	// priorDecay is the factor by which every prior grouping costs less than the one after it, if set
+	priorDecay float64
+
+	// pinnedGroups are groups that students formed themselves for projects, by name
+	pinnedGroups map[string][]api.Group
+
+	// projectSizes override the default sizes of groups for projects, by name
+	projectSizes map[string]api.ProjectSize
+
+	// projectRoles are the roles members take on in groups for projects, by name
+	projectRoles map[string][]string
+
+	// availability are the hours of the week students are free to meet, by NetID
+	availability map[string][]api.TimeSlot
+
+	// balanceSkills determines if groups are balanced by skill, and balancedSkills are the skills
+	// to balance, or empty for every skill
+	balanceSkills  bool
+	balancedSkills []string
+
+	// explain determines if what happens to every student while generating groupings is recorded, to explain
+	// how they ended up in their groups
+	explain bool
+}
+
+// WithSeed fixes the seed of the random source used for every generation, so that the same
+// roster and priors will always produce the same grouping
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+		o.seeded = true
+	}
+}
+
+// WithScorer registers a scorer with the objective, which is the weighted sum of all registered scores.
+// Registering any scorer replaces the default objective, so NewRepairingScorer must be registered as well
+// if repairings should still be minimized. Goals added by other options, like WithSpreadAttribute, are kept.
+func WithScorer(scorer Scorer, weight float64) Option {
+	return func(o *options) {
+		o.scorers = append(o.scorers, weightedScorer{Scorer: scorer, weight: weight})
+	}
+}
+
+// WithConstraints requires every grouping to keep students together or apart as the rules say. If the rules
+// cannot all be satisfied, generation fails with a *ConstraintConflictError.
+func WithConstraints(constraints []api.Constraint) Option {
+	return func(o *options) {
+		o.constraints = append(o.constraints, constraints...)
+	}
+}
+
+// WithPriorWeights sets how much repeating a collaboration from each prior grouping costs, in the same order as
+// the prior groupings are given. Repeating a partner costs the largest weight of the prior groupings the students
+// shared; prior groupings without a weight cost one, unless WithPriorDecay is used.
+func WithPriorWeights(weights []float64) Option {
+	return func(o *options) {
+		o.priorWeights = weights
+	}
+}
+
+// WithPriorDecay makes repeating collaborations from older prior groupings cost less. The prior groupings are taken
+// to be in order from oldest to newest: the newest costs one and every other costs the decay times the one after it.
+// Weights set with WithPriorWeights take precedence.
+func WithPriorDecay(decay float64) Option {
+	return func(o *options) {
+		o.priorDecay = decay
+	}
+}
+
+// WithPinnedGroups keeps the groups in the partial groupings as they are for the projects with the same names,
+// only grouping the rest of the class. Pinned groups count as collaborations like any other, and their members
+// are never moved.
+func WithPinnedGroups(groupings []api.ProjectGrouping) Option {
+	return func(o *options) {
+		if o.pinnedGroups == nil {
+			o.pinnedGroups = map[string][]api.Group{}
+		}
+		for _, grouping := range groupings {
+			o.pinnedGroups[grouping.Name] = append(o.pinnedGroups[grouping.Name], grouping.Groups...)
+		}
+	}
+}
+
+// WithProjectSizes overrides the default sizes of groups for the named projects with either their own optimal
+// size or a range of sizes. Projects without either are left with the defaults. Students keep their history of
+// collaborations across projects of different sizes.
+func WithProjectSizes(sizes []api.ProjectSize) Option {
+	return func(o *options) {
+		if o.projectSizes == nil {
+			o.projectSizes = map[string]api.ProjectSize{}
+		}
+		for _, size := range sizes {
+			if size.OptimalSize > 0 || size.MaxSize > 0 {
+				o.projectSizes[size.Name] = size
+			}
+		}
+	}
+}
+
+// WithProjectRoles assigns roles to the members of every group for the named projects. Roles are rotated so
+// that students take on roles they haven't had yet, in earlier projects or in prior groupings, whenever they can.
+func WithProjectRoles(roles []api.ProjectRoles) Option {
+	return func(o *options) {
+		if o.projectRoles == nil {
+			o.projectRoles = map[string][]string{}
+		}
+		for _, projectRoles := range roles {
+			if len(projectRoles.Roles) > 0 {
+				o.projectRoles[projectRoles.Name] = projectRoles.Roles
+			}
+		}
+	}
+}
+
+// WithAvailability adds a goal to the objective of giving every group at least the given number of hours in the
+// week when all of its members are free to meet. The weight determines how much every hour a group is short
+// matters compared to a repairing. Students whose availability isn't given are assumed to be free whenever their
+// group is, and the hours every group shares are recorded as its meetings.
+func WithAvailability(availability []api.Availability, minHours int, weight float64) Option {
+	return func(o *options) {
+		if o.availability == nil {
+			o.availability = map[string][]api.TimeSlot{}
+		}
+		for _, studentAvailability := range availability {
+			o.availability[studentAvailability.Student] = append(o.availability[studentAvailability.Student], studentAvailability.Free...)
+		}
+		o.additionalScorers = append(o.additionalScorers, weightedScorer{Scorer: NewAvailabilityScorer(minHours), weight: weight})
+	}
+}
+
+// WithSkillBalance adds a goal to the objective of giving every group a similar average and spread of skill scores
+// to the class as a whole, for the named skills or for every skill if none are named. The weight determines how
+// much every standard deviation a group is off by matters compared to a repairing. Strategies that start from a
+// full grouping start from a snake draft by skill instead of a random grouping.
+func WithSkillBalance(skills []string, weight float64) Option {
+	return func(o *options) {
+		o.balanceSkills = true
+		o.balancedSkills = skills
+		o.additionalScorers = append(o.additionalScorers, weightedScorer{Scorer: NewSkillBalanceScorer(skills), weight: weight})
+	}
+}
+
+// WithPreferences adds a goal to the objective of honoring students' preferences about who they work with.
+// The weight determines how much an honored request matters compared to avoiding a repairing. How many of
+// every student's requests were honored is reported in the summary.
+func WithPreferences(preferences []api.Preference, weight float64) Option {
+	return func(o *options) {
+		o.preferences = append(o.preferences, preferences...)
+		o.additionalScorers = append(o.additionalScorers, weightedScorer{Scorer: NewPreferenceScorer(preferences), weight: weight})
+	}
+}
+
+// WithFairness adds a goal to the objective of spreading unavoidable repeated collaborations fairly, by keeping
+// both the most repeats any one student has and the variance of repeats across students low. The weight
+// determines how much each of these matters compared to the total number of repairings.
+func WithFairness(weight float64) Option {
+	return func(o *options) {
+		o.additionalScorers = append(o.additionalScorers,
+			weightedScorer{Scorer: NewMaxRepeatsScorer(), weight: weight},
+			weightedScorer{Scorer: NewRepeatVarianceScorer(), weight: weight},
+		)
+	}
+}
+
+// WithSizeRotation adds a goal to the objective of giving the places in odd-sized groups, which are made when the
+// class can't be evenly divided, to students who haven't been in one yet. The weight determines how much each
+// student put in a second odd-sized group matters compared to a repairing.
+func WithSizeRotation(weight float64) Option {
+	return func(o *options) {
+		o.additionalScorers = append(o.additionalScorers, weightedScorer{Scorer: NewSizeRotationScorer(), weight: weight})
+	}
+}
+
+// WithSpreadAttribute adds a goal to the objective of spreading the values of an attribute, like a
+// student's major or year, as evenly as possible across groups. The weight determines how much the
+// goal matters compared to avoiding repairings.
+func WithSpreadAttribute(attribute string, weight float64) Option {
+	return func(o *options) {
+		o.additionalScorers = append(o.additionalScorers, weightedScorer{Scorer: NewAttributeSpreadScorer(attribute), weight: weight})
+	}
+}
+
+// WithNoIsolation adds a goal to the objective of never leaving a student as the only member of their
+// group to hold the value of an attribute. If the value is empty, every value of the attribute counts.
+// The weight determines how much the goal matters compared to avoiding repairings.
+func WithNoIsolation(attribute, value string, weight float64) Option {
+	return func(o *options) {
+		o.additionalScorers = append(o.additionalScorers, weightedScorer{Scorer: NewIsolationScorer(attribute, value), weight: weight})
+	}
+}
+
+// WithExplanations records what happens to every student while generating groupings, and explains in the summary
+// how every student ended up in their group for every project, including where they had worked with anyone in it
+// before. Strategies that don't grow groups one student at a time only explain who students ended up with.
+func WithExplanations() Option {
+	return func(o *options) {
+		o.explain = true
+	}
+}
+
+// newOptions applies the given options over the defaults
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if len(o.scorers) == 0 {
+		// by default, we only care about how many times students are grouped with prior collaborators
+		o.scorers = []weightedScorer{{Scorer: NewRepairingScorer(), weight: 1}}
+	}
+	o.scorers = append(o.scorers, o.additionalScorers...)
+	return o
+}
+
+// priorWeight determines how much repeating a collaboration from the prior grouping at the index costs
+func (o *options) priorWeight(index, numPriors int) float64 {
+	switch {
+	case index < len(o.priorWeights):
+		return o.priorWeights[index]
+	case o.priorDecay > 0:
+		return math.Pow(o.priorDecay, float64(numPriors-1-index))
+	default:
+		return 1
+	}
+}
+
+// summarize records what the options track about the projects in the summary
+func (o *options) summarize(projects []*Project, summary *api.Summary) {
+	for student, repeats := range studentRepeats(projects) {
+		if summary.Repeats == nil {
+			summary.Repeats = map[string]int{}
+		}
+		summary.Repeats[student.NetID] = repeats
+	}
+	if len(o.preferences) > 0 {
+		summary.Satisfaction = satisfaction(projects, o.preferences)
+	}
+	summary.Skills = skillProfiles(projects)
+	if o.explain {
+		summary.Explanations = explanations(projects)
+	}
+}
+
+// hasSoftObjectives determines if the objective measures anything other than repairings
+func (o *options) hasSoftObjectives() bool {
+	for _, scorer := range o.scorers {
+		if _, repairing := scorer.Scorer.(*repairingScorer); !repairing {
+			return true
+		}
+	}
+	return false
+}
+
+// score determines the value of the objective for the projects
+func (o *options) score(projects []*Project) float64 {
+	var score float64
+	for _, scorer := range o.scorers {
+		score += scorer.weight * scorer.Score(projects)
+	}
+	return score
+}
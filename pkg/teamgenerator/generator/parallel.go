@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewParallelClassGrouping returns a ClassGrouping that runs independent generations on a number of workers at once,
+// each with a seed derived from the seed of the parallel generation, and keeps the best-scoring grouping. The workers
+// are created with the given constructor, which receives all of the options as well as the derived seed. If the
+// number of workers is not positive, one worker is used for every CPU.
+func NewParallelClassGrouping(workers int, newClassGrouping func(opts ...Option) ClassGrouping, opts ...Option) ClassGrouping {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	return &parallelClassGrouping{
+		classGrouping:    classGrouping{options: newOptions(opts)},
+		workers:          workers,
+		newClassGrouping: newClassGrouping,
+		workerOptions:    opts,
+	}
+}
+
+type parallelClassGrouping struct {
+	classGrouping
+	workers          int
+	newClassGrouping func(opts ...Option) ClassGrouping
+	workerOptions    []Option
+}
+
+// Generate generates a class grouping from a roster
+func (g *parallelClassGrouping) Generate(students []api.Student, groupingNames []string) api.ClassGrouping {
+	return g.GenerateWithPriors(students, nil, groupingNames)
+}
+
+// GenerateWithPriors generates a class grouping from a roster, taking into account prior groupings
+func (g *parallelClassGrouping) GenerateWithPriors(students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string) api.ClassGrouping {
+	grouping, _ := g.GenerateWithPriorsContext(context.Background(), students, priorGroupings, groupingNames, Budget{})
+	return grouping
+}
+
+// GenerateContext generates a class grouping from a roster, within the given budget
+func (g *parallelClassGrouping) GenerateContext(ctx context.Context, students []api.Student, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	return g.GenerateWithPriorsContext(ctx, students, nil, groupingNames, budget)
+}
+
+// GenerateWithPriorsContext generates a class grouping from a roster, taking into account prior groupings,
+// within the given budget. Every worker is given the full budget. The error returned is that of the worker
+// whose grouping was chosen, or that of the first worker if none of them found a grouping.
+func (g *parallelClassGrouping) GenerateWithPriorsContext(ctx context.Context, students []api.Student, priorGroupings []api.ProjectGrouping, groupingNames []string, budget Budget) (api.ClassGrouping, error) {
+	_, seed := g.newRandom()
+
+	groupings := make([]api.ClassGrouping, g.workers)
+	errs := make([]error, g.workers)
+	var wg sync.WaitGroup
+	for i, workerSeed := range deriveSeeds(seed, g.workers) {
+		opts := append(append([]Option{}, g.workerOptions...), WithSeed(workerSeed))
+		worker := g.newClassGrouping(opts...)
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			groupings[i], errs[i] = worker.GenerateWithPriorsContext(ctx, students, priorGroupings, groupingNames, budget)
+		}(i)
+	}
+	wg.Wait()
+
+	chosen := -1
+	var workers []api.WorkerSummary
+	for i, grouping := range groupings {
+		summary := api.WorkerSummary{
+			Seed:       grouping.Seed,
+			Repairings: grouping.Summary.Repairings,
+			Score:      grouping.Summary.Score,
+			Attempts:   grouping.Summary.Attempts,
+			Partial:    grouping.Summary.Partial,
+		}
+		if errs[i] != nil {
+			summary.Error = errs[i].Error()
+		}
+		workers = append(workers, summary)
+
+		if !foundGrouping(grouping, errs[i]) {
+			continue
+		}
+		if chosen < 0 || grouping.Summary.Score < groupings[chosen].Summary.Score {
+			chosen = i
+		}
+	}
+
+	if chosen < 0 {
+		best := newBestGrouping(seed, &g.options)
+		best.grouping.Summary.Workers = workers
+		return best.result(0, errs[0])
+	}
+
+	grouping := groupings[chosen]
+	grouping.Seed = seed
+	grouping.Summary.Workers = workers
+	return grouping, errs[chosen]
+}
+
+// foundGrouping determines if a generation that returned the error found a grouping
+func foundGrouping(grouping api.ClassGrouping, err error) bool {
+	if err == nil {
+		return true
+	}
+	partial, ok := err.(*PartialGroupingError)
+	return ok && partial.Found()
+}
+
+// deriveSeeds derives the seeds for a number of workers from a single seed
+func deriveSeeds(seed int64, workers int) []int64 {
+	random := rand.New(rand.NewSource(seed))
+	var seeds []int64
+	for i := 0; i < workers; i++ {
+		seeds = append(seeds, random.Int63())
+	}
+	return seeds
+}
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParallelKeepsBestWorker(t *testing.T) {
+	roster := testRoster(15)
+	projects := []string{"first", "second", "third", "fourth", "fifth", "sixth", "seventh", "eighth"}
+	schedule := Schedule{InitialTemperature: 1, CoolingRate: 0.5, MinimumTemperature: 0.1, SwapsPerTemperature: 10}
+	newClassGrouping := func(opts ...Option) ClassGrouping {
+		return NewAnnealingClassGrouping(3, false, schedule, opts...)
+	}
+	generator := NewParallelClassGrouping(4, newClassGrouping, WithSeed(42))
+
+	grouping, err := generator.GenerateContext(context.Background(), roster, projects, Budget{})
+	if err != nil {
+		t.Fatalf("failed to generate grouping: %v", err)
+	}
+
+	if grouping.Seed != 42 {
+		t.Errorf("did not record the seed of the parallel generation, expected %d, got %d", 42, grouping.Seed)
+	}
+	if len(grouping.Summary.Workers) != 4 {
+		t.Fatalf("expected a summary for each of %d workers, got %d", 4, len(grouping.Summary.Workers))
+	}
+	checkEveryStudentGroupedOnce(t, "parallel", roster, grouping)
+
+	seeds := map[int64]bool{}
+	for i, worker := range grouping.Summary.Workers {
+		if worker.Score < grouping.Summary.Score {
+			t.Errorf("worker %d found a better grouping than the one chosen, %g < %g", i, worker.Score, grouping.Summary.Score)
+		}
+		seeds[worker.Seed] = true
+	}
+	if len(seeds) != 4 {
+		t.Errorf("expected every worker to use a different seed, got %v", seeds)
+	}
+
+	again, _ := generator.GenerateContext(context.Background(), roster, projects, Budget{})
+	if !reflect.DeepEqual(again, grouping) {
+		t.Errorf("parallel generation with the same seed did not create the same grouping")
+	}
+}
@@ -0,0 +1,99 @@
+package generator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestGenerateKeepsPinnedGroups(t *testing.T) {
+	roster := testRoster(12)
+	pinned := testGrouping(roster, []int{0, 1}, []int{2, 3, 4})
+	schedule := Schedule{InitialTemperature: 1, CoolingRate: 0.8, MinimumTemperature: 0.1, SwapsPerTemperature: 50}
+
+	generators := map[string]ClassGrouping{
+		"reshuffle": NewClassGrouping(3, false, WithSeed(42), WithPinnedGroups([]api.ProjectGrouping{pinned})),
+		"annealing": NewAnnealingClassGrouping(3, false, schedule, WithSeed(42), WithPinnedGroups([]api.ProjectGrouping{pinned})),
+	}
+
+	for name, generator := range generators {
+		grouping, err := generator.GenerateContext(context.Background(), roster, []string{"design", "final"}, Budget{Attempts: 20})
+		if !foundGrouping(grouping, err) {
+			t.Errorf("%s: failed to generate grouping: %v", name, err)
+			continue
+		}
+		checkEveryStudentGroupedOnce(t, name, roster, grouping)
+
+		design := grouping.Projects[0]
+		if !reflect.DeepEqual(design.Groups[:2], pinned.Groups) {
+			t.Errorf("%s: pinned groups were not kept:\n\twanted:\n\t%v\n\tgot:\n\t%v", name, pinned.Groups, design.Groups[:2])
+		}
+		var sizes []int
+		for _, group := range design.Groups[2:] {
+			sizes = append(sizes, len(group.Members))
+		}
+		if expected := []int{3, 4}; !reflect.DeepEqual(sizes, expected) {
+			t.Errorf("%s: expected the rest of the class in groups of sizes %v, got %v", name, expected, sizes)
+		}
+	}
+}
+
+func TestCheckPinnedGroups(t *testing.T) {
+	roster := testRoster(9)
+	var testCases = []struct {
+		name              string
+		pinned            api.ProjectGrouping
+		constraints       []api.Constraint
+		expectedConflicts []string
+	}{
+		{
+			name:   "pinned groups with constraints",
+			pinned: testGrouping(roster, []int{0, 1}),
+			constraints: []api.Constraint{
+				{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+				{Kind: api.MustBeTogether, Student: "s2@duke.edu", Partner: "s3@duke.edu"},
+			},
+		},
+		{
+			name:   "student pinned twice",
+			pinned: testGrouping(roster, []int{0, 1}, []int{1, 2}),
+			expectedConflicts: []string{
+				"for design, s1@duke.edu is pinned to more than one group",
+			},
+		},
+		{
+			name:   "student off the roster",
+			pinned: api.ProjectGrouping{Name: "design", Groups: []api.Group{{Members: []api.Student{roster[0], {NetID: "xyz@duke.edu"}}}}},
+			expectedConflicts: []string{
+				"for design, pinned group 1 names xyz@duke.edu, who is not on the roster",
+			},
+		},
+		{
+			name:   "constraints against pinned groups",
+			pinned: testGrouping(roster, []int{0, 1}),
+			constraints: []api.Constraint{
+				{Kind: api.MustBeTogether, Student: "s0@duke.edu", Partner: "s2@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s1@duke.edu", Partner: "s0@duke.edu"},
+			},
+			expectedConflicts: []string{
+				`for design, rule "apart s1@duke.edu s0@duke.edu (all projects)" conflicts with pinned group 1`,
+				`for design, rules "together s0@duke.edu s2@duke.edu (all projects)" require students to be together who are not pinned to the same group`,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		generator := &classGrouping{optimalGroupSize: 3, options: newOptions([]Option{WithPinnedGroups([]api.ProjectGrouping{testCase.pinned}), WithConstraints(testCase.constraints)})}
+		err := generator.checkConstraints(roster, []string{"design"})
+
+		var actualConflicts []string
+		if err != nil {
+			actualConflicts = err.(*ConstraintConflictError).Conflicts
+		}
+		if !reflect.DeepEqual(actualConflicts, testCase.expectedConflicts) {
+			t.Errorf("%s: correct conflicts not found:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedConflicts, actualConflicts)
+		}
+	}
+}
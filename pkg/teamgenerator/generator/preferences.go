@@ -0,0 +1,73 @@
+package generator
+
+import "github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+
+// NewPreferenceScorer returns a scorer that counts the requests in students' preferences about who they work
+// with that were not honored
+func NewPreferenceScorer(preferences []api.Preference) Scorer {
+	return &preferenceScorer{preferences: preferences}
+}
+
+type preferenceScorer struct {
+	preferences []api.Preference
+}
+
+// Score counts the requests that were not honored in the projects: a request to work with someone is honored
+// when they share a group, and a request not to is honored when they don't. Requests naming students who are
+// not grouped are not counted.
+func (s *preferenceScorer) Score(projects []*Project) float64 {
+	unhonored := 0
+	for _, result := range satisfaction(projects, s.preferences) {
+		unhonored += result.Requested - result.Honored
+	}
+	return float64(unhonored)
+}
+
+// satisfaction determines how many of every student's requests were honored in the projects, in the order the
+// students first appear in the preferences
+func satisfaction(projects []*Project, preferences []api.Preference) []api.Satisfaction {
+	var results []api.Satisfaction
+	indexOf := map[string]int{}
+	for _, project := range projects {
+		groupOf := map[string]int{}
+		for i, group := range project.Groups {
+			for _, member := range group.Members() {
+				groupOf[member.NetID] = i
+			}
+		}
+
+		for _, preference := range preferences {
+			if !appliesTo(preference.Projects, project.Name) {
+				continue
+			}
+			group, grouped := groupOf[preference.Student]
+			if !grouped {
+				continue
+			}
+
+			index, seen := indexOf[preference.Student]
+			if !seen {
+				index = len(results)
+				indexOf[preference.Student] = index
+				results = append(results, api.Satisfaction{Student: preference.Student})
+			}
+
+			for _, requests := range []struct {
+				netIDs   []string
+				together bool
+			}{{netIDs: preference.Preferred, together: true}, {netIDs: preference.Avoided, together: false}} {
+				for _, netID := range requests.netIDs {
+					partnerGroup, partnerGrouped := groupOf[netID]
+					if !partnerGrouped {
+						continue
+					}
+					results[index].Requested++
+					if (partnerGroup == group) == requests.together {
+						results[index].Honored++
+					}
+				}
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestSatisfaction(t *testing.T) {
+	var roster []*Student
+	for _, student := range testRoster(4) {
+		roster = append(roster, NewStudent(student))
+	}
+	indexRoster(roster)
+	design := testProject(roster, []int{0, 1}, []int{2, 3})
+	final := testProject(roster, []int{0, 2}, []int{1, 3})
+	design.Name, final.Name = "design", "final"
+
+	var testCases = []struct {
+		name                 string
+		preferences          []api.Preference
+		expectedSatisfaction []api.Satisfaction
+		expectedScore        float64
+	}{
+		{
+			name:                 "honored preference",
+			preferences:          []api.Preference{{Student: "s0@duke.edu", Preferred: []string{"s1@duke.edu"}, Projects: []string{"design"}}},
+			expectedSatisfaction: []api.Satisfaction{{Student: "s0@duke.edu", Honored: 1, Requested: 1}},
+			expectedScore:        0,
+		},
+		{
+			name:                 "preference across projects",
+			preferences:          []api.Preference{{Student: "s0@duke.edu", Preferred: []string{"s1@duke.edu"}, Avoided: []string{"s3@duke.edu"}}},
+			expectedSatisfaction: []api.Satisfaction{{Student: "s0@duke.edu", Honored: 3, Requested: 4}},
+			expectedScore:        1,
+		},
+		{
+			name: "broken anti-preference",
+			preferences: []api.Preference{
+				{Student: "s2@duke.edu", Avoided: []string{"s3@duke.edu"}, Projects: []string{"design"}},
+				{Student: "s1@duke.edu", Preferred: []string{"s3@duke.edu"}, Projects: []string{"final"}},
+			},
+			expectedSatisfaction: []api.Satisfaction{{Student: "s2@duke.edu", Honored: 0, Requested: 1}, {Student: "s1@duke.edu", Honored: 1, Requested: 1}},
+			expectedScore:        1,
+		},
+		{
+			name:                 "students off the roster are not counted",
+			preferences:          []api.Preference{{Student: "s0@duke.edu", Preferred: []string{"xyz@duke.edu"}}},
+			expectedSatisfaction: []api.Satisfaction{{Student: "s0@duke.edu", Honored: 0, Requested: 0}},
+			expectedScore:        0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		projects := []*Project{design, final}
+		if actual, expected := satisfaction(projects, testCase.preferences), testCase.expectedSatisfaction; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: did not determine satisfaction correctly, expected %v, got %v", testCase.name, expected, actual)
+		}
+		if actual, expected := NewPreferenceScorer(testCase.preferences).Score(projects), testCase.expectedScore; actual != expected {
+			t.Errorf("%s: did not count unhonored requests correctly, expected %g, got %g", testCase.name, expected, actual)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"fmt"
 	"math"
 	"sort"
 
@@ -15,24 +16,120 @@ type Project struct {
 	// Name is the name of the project for which groups are created
 	Name string
 
+	// Roles are the roles members of every group take on, if the project has any
+	Roles []string
+
 	// UngroupedStudents are the students in the class that have not yet been assigned to a group for this project
 	UngroupedStudents []*Student
+
+	// ungroupedAt holds the position of every ungrouped student in UngroupedStudents, by their index, plus one so
+	// that students who have been grouped are zero
+	ungroupedAt []int
+
+	// usualSize is the most common size of the groups, if any size is, as of when there were sizedGroups groups
+	usualSize, sizedGroups int
+	hasUsualSize           bool
+
+	// lockedStudents are students whose group for this project may not be changed
+	lockedStudents map[*Student]bool
+
+	// separatedStudents are pairs of students that may not share a group for this project
+	separatedStudents map[pair]bool
+
+	// decisions describe what happened to every student while grouping this project, in order, if that is
+	// being recorded to explain the grouping
+	decisions map[*Student][]string
 }
 
-// NewProject initializes a new project grouping for the given roster
-func NewProject(name string, roster []*Student, optimalGroupSize int, preferSmallerGroups bool) *Project {
-	project := Project{Name: name}
+// NewProject initializes a new project grouping for the given roster. Students are only grouped with others
+// in their section, so the sizes of groups are determined for every section on its own.
+func NewProject(name string, roster []*Student, sizing GroupSizing) (*Project, error) {
+	project := Project{Name: name, lockedStudents: map[*Student]bool{}, separatedStudents: map[pair]bool{}}
 
 	for _, student := range roster {
-		project.UngroupedStudents = append(project.UngroupedStudents, student)
+		project.MarkStudentUngrouped(student)
+	}
+
+	sections, sectionSizes := sectionSizes(roster)
+	for _, section := range sections {
+		groupSizes, err := sizing.groupSizes(sectionSizes[section])
+		if err != nil {
+			return nil, fmt.Errorf("could not size groups for %s: %v", name, err)
+		}
+		for _, size := range groupSizes {
+			group := NewGroup(size)
+			group.Section = section
+			project.Groups = append(project.Groups, group)
+		}
 	}
 
-	groupSizes := determineGroupSizes(len(roster), optimalGroupSize, preferSmallerGroups)
-	for _, size := range groupSizes {
-		project.Groups = append(project.Groups, NewGroup(size))
+	return &project, nil
+}
+
+// GroupSizing determines the sizes of the groups for a project. If an optimal size is given, some groups are made
+// smaller or larger than it when the class can't be evenly divided; otherwise, every group must have a size in the
+// range between the minimum and maximum sizes.
+type GroupSizing struct {
+	OptimalSize         int
+	MinSize, MaxSize    int
+	PreferSmallerGroups bool
+}
+
+// groupSizes determines the sizes of the groups for the given number of students, sorted smallest to largest
+func (s GroupSizing) groupSizes(numStudents int) ([]int, error) {
+	if s.OptimalSize > 0 {
+		return determineGroupSizes(numStudents, s.OptimalSize, s.PreferSmallerGroups), nil
 	}
+	return determineGroupSizesInRange(numStudents, s.MinSize, s.MaxSize, s.PreferSmallerGroups)
+}
 
-	return &project
+// IsOddSized determines if the group is not of the usual size for the project, so that students
+// can take turns being in such groups
+func (p *Project) IsOddSized(group *Group) bool {
+	// groups are only ever added to a project, so the usual size only needs to be worked out again when there are more
+	if p.sizedGroups != len(p.Groups) {
+		var sizes []int
+		for _, other := range p.Groups {
+			sizes = append(sizes, other.DesiredSize)
+		}
+		p.usualSize, p.hasUsualSize = usualSize(sizes)
+		p.sizedGroups = len(p.Groups)
+	}
+	return p.hasUsualSize && group.DesiredSize != p.usualSize
+}
+
+// usualSize determines the most common of the group sizes. If no size is more common than all of the
+// others, there is no usual size.
+func usualSize(sizes []int) (int, bool) {
+	counts := map[int]int{}
+	for _, size := range sizes {
+		counts[size]++
+	}
+
+	usual, unique := 0, false
+	for size, count := range counts {
+		switch {
+		case count > counts[usual]:
+			usual, unique = size, true
+		case count == counts[usual]:
+			unique = false
+		}
+	}
+	return usual, unique
+}
+
+// sectionSizes counts the students in every section of the roster, returning the sections in the order
+// they first appear
+func sectionSizes(roster []*Student) ([]string, map[string]int) {
+	var sections []string
+	sizes := map[string]int{}
+	for _, student := range roster {
+		if _, seen := sizes[student.Section]; !seen {
+			sections = append(sections, student.Section)
+		}
+		sizes[student.Section]++
+	}
+	return sections, sizes
 }
 
 // ToAPIProjectGrouping converts this project to a serializable format
@@ -71,6 +168,36 @@ func determineGroupSizes(numStudents, optimalGroupSize int, preferSmallerGroups
 	return groupSizes
 }
 
+// determineGroupSizesInRange will determine sizes for groups that all fall between the minimum and maximum size,
+// making as many groups as possible if smaller groups are preferred and as few as possible otherwise. The students
+// are spread evenly over the groups and the list of group sizes is sorted, smallest to largest.
+func determineGroupSizesInRange(numStudents, minGroupSize, maxGroupSize int, preferSmallerGroups bool) ([]int, error) {
+	if minGroupSize < 1 || maxGroupSize < minGroupSize {
+		return nil, fmt.Errorf("group sizes must be a range of positive sizes, got %d-%d", minGroupSize, maxGroupSize)
+	}
+
+	fewestGroups := (numStudents + maxGroupSize - 1) / maxGroupSize
+	mostGroups := numStudents / minGroupSize
+	if fewestGroups > mostGroups {
+		return nil, fmt.Errorf("%d students cannot be divided into groups of %d-%d", numStudents, minGroupSize, maxGroupSize)
+	}
+
+	numGroups := fewestGroups
+	if preferSmallerGroups {
+		numGroups = mostGroups
+	}
+
+	groupSizes := []int{}
+	for i := 0; i < numGroups; i++ {
+		size := numStudents / numGroups
+		if i >= numGroups-numStudents%numGroups {
+			size++
+		}
+		groupSizes = append(groupSizes, size)
+	}
+	return groupSizes, nil
+}
+
 // determineSmallerGroupSizes will determine group sizes with the following logic:
 // starting with a list of group sizes, we remove one member from the largest group and add them to the
 // smallest group recursively, until the biggest difference in membership is at most 1.
@@ -178,21 +305,96 @@ func indiciesOfTwoSmallest(numbers []int) (int, int) {
 	return smallestIndex, secondSmallestIndex
 }
 
-// MarkStudentGrouped removes the given student from the list of unassigned students in this project
+// MarkStudentGrouped removes the given student from the list of unassigned students in this project. The last
+// student on the list takes their place, so the order of the list is not kept.
 func (p *Project) MarkStudentGrouped(student *Student) {
-	removeIndex := -1
-	for i, unassignedStudent := range p.UngroupedStudents {
-		if unassignedStudent.Equals(student) {
-			removeIndex = i
-		}
+	if !p.isUngrouped(student) {
+		return
 	}
 
-	if removeIndex > 0 {
-		p.UngroupedStudents = append(p.UngroupedStudents[:removeIndex], p.UngroupedStudents[removeIndex:]...)
-	}
+	removeIndex, last := p.ungroupedAt[student.index]-1, p.UngroupedStudents[len(p.UngroupedStudents)-1]
+	p.UngroupedStudents[removeIndex] = last
+	p.ungroupedAt[last.index] = removeIndex + 1
+	p.UngroupedStudents = p.UngroupedStudents[:len(p.UngroupedStudents)-1]
+	p.ungroupedAt[student.index] = 0
 }
 
 // MarkStudentUngrouped adds the given student to the list of unassigned students in this project
 func (p *Project) MarkStudentUngrouped(student *Student) {
+	if p.isUngrouped(student) {
+		return
+	}
+
+	for len(p.ungroupedAt) <= student.index {
+		p.ungroupedAt = append(p.ungroupedAt, 0)
+	}
 	p.UngroupedStudents = append(p.UngroupedStudents, student)
+	p.ungroupedAt[student.index] = len(p.UngroupedStudents)
+}
+
+// isUngrouped determines if the student is on the list of unassigned students in this project
+func (p *Project) isUngrouped(student *Student) bool {
+	return student.index < len(p.ungroupedAt) && p.ungroupedAt[student.index] > 0
+}
+
+// Lock keeps the student in their current group for this project
+func (p *Project) Lock(student *Student) {
+	p.lockedStudents[student] = true
+}
+
+// IsLocked determines if the student's group for this project may not be changed
+func (p *Project) IsLocked(student *Student) bool {
+	return p.lockedStudents[student]
+}
+
+// Separate forbids the students from sharing a group for this project
+func (p *Project) Separate(student, partner *Student) {
+	p.separatedStudents[newPair(student, partner)] = true
+}
+
+// CanJoin determines if the student may join the group, which must be for their section and may not hold anyone
+// they must be kept apart from
+func (p *Project) CanJoin(group *Group, student *Student) bool {
+	if group.Section != student.Section {
+		return false
+	}
+	if len(p.separatedStudents) == 0 {
+		return true
+	}
+	for _, member := range group.members {
+		if p.separatedStudents[newPair(member, student)] {
+			return false
+		}
+	}
+	return true
+}
+
+// CanJoinAll determines if all of the students may join the group together
+func (p *Project) CanJoinAll(group *Group, students []*Student) bool {
+	for i, student := range students {
+		if !p.CanJoin(group, student) {
+			return false
+		}
+		for _, other := range students[i+1:] {
+			if p.separatedStudents[newPair(student, other)] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// separations counts the pairs of students sharing a group that should have been kept apart
+func (p *Project) separations() int {
+	separations := 0
+	for _, group := range p.Groups {
+		for i, member := range group.members {
+			for _, other := range group.members[i+1:] {
+				if p.separatedStudents[newPair(member, other)] {
+					separations++
+				}
+			}
+		}
+	}
+	return separations
 }
@@ -1,7 +1,9 @@
 package generator
 
 import (
+	"errors"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -208,3 +210,139 @@ func TestDetermineGroupSizes(t *testing.T) {
 		}
 	}
 }
+
+func TestDetermineGroupSizesInRange(t *testing.T) {
+	var testCases = []struct {
+		name                string
+		numStudents         int
+		minGroupSize        int
+		maxGroupSize        int
+		preferSmallerGroups bool
+		expectedGroupSizes  []int
+		expectedError       error
+	}{
+		{
+			name:               "fewest groups",
+			numStudents:        20,
+			minGroupSize:       2,
+			maxGroupSize:       4,
+			expectedGroupSizes: []int{4, 4, 4, 4, 4},
+		},
+		{
+			name:                "most groups",
+			numStudents:         21,
+			minGroupSize:        2,
+			maxGroupSize:        4,
+			preferSmallerGroups: true,
+			expectedGroupSizes:  []int{2, 2, 2, 2, 2, 2, 2, 2, 2, 3},
+		},
+		{
+			name:               "students are spread evenly",
+			numStudents:        22,
+			minGroupSize:       3,
+			maxGroupSize:       5,
+			expectedGroupSizes: []int{4, 4, 4, 5, 5},
+		},
+		{
+			name:          "no sizes fit",
+			numStudents:   7,
+			minGroupSize:  4,
+			maxGroupSize:  5,
+			expectedError: errors.New("7 students cannot be divided into groups of 4-5"),
+		},
+		{
+			name:          "empty range",
+			numStudents:   7,
+			minGroupSize:  4,
+			maxGroupSize:  3,
+			expectedError: errors.New("group sizes must be a range of positive sizes, got 4-3"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := determineGroupSizesInRange(testCase.numStudents, testCase.minGroupSize, testCase.maxGroupSize, testCase.preferSmallerGroups)
+		if !reflect.DeepEqual(err, testCase.expectedError) {
+			t.Errorf("%s: did not fail correctly, expected %v, got %v", testCase.name, testCase.expectedError, err)
+		}
+		if expected := testCase.expectedGroupSizes; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: did not create group sizes correctly, expected %v, got %v", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestMarkStudentGrouped(t *testing.T) {
+	var testCases = []struct {
+		name              string
+		grouped           []int
+		ungrouped         []int
+		expectedUngrouped []string
+	}{
+		{
+			name:              "nobody grouped",
+			expectedUngrouped: []string{"s0@duke.edu", "s1@duke.edu", "s2@duke.edu", "s3@duke.edu"},
+		},
+		{
+			name:              "only the first grouped",
+			grouped:           []int{0},
+			expectedUngrouped: []string{"s1@duke.edu", "s2@duke.edu", "s3@duke.edu"},
+		},
+		{
+			name:              "first and last grouped",
+			grouped:           []int{0, 3},
+			expectedUngrouped: []string{"s1@duke.edu", "s2@duke.edu"},
+		},
+		{
+			name:              "grouped twice",
+			grouped:           []int{1, 1},
+			expectedUngrouped: []string{"s0@duke.edu", "s2@duke.edu", "s3@duke.edu"},
+		},
+		{
+			name:              "everyone grouped",
+			grouped:           []int{2, 0, 3, 1},
+			expectedUngrouped: []string{},
+		},
+		{
+			name:              "grouped and ungrouped again",
+			grouped:           []int{0, 1, 2},
+			ungrouped:         []int{1, 1},
+			expectedUngrouped: []string{"s1@duke.edu", "s3@duke.edu"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		var roster []*Student
+		for _, student := range testRoster(4) {
+			roster = append(roster, NewStudent(student))
+		}
+		indexRoster(roster)
+		project, err := NewProject("test", roster, GroupSizing{OptimalSize: 2})
+		if err != nil {
+			t.Fatalf("%s: failed to create project: %v", testCase.name, err)
+		}
+
+		for _, index := range testCase.grouped {
+			project.MarkStudentGrouped(roster[index])
+		}
+		for _, index := range testCase.ungrouped {
+			project.MarkStudentUngrouped(roster[index])
+		}
+
+		actualUngrouped := []string{}
+		for _, student := range project.UngroupedStudents {
+			actualUngrouped = append(actualUngrouped, student.NetID)
+		}
+		sort.Strings(actualUngrouped)
+		if expected := testCase.expectedUngrouped; !reflect.DeepEqual(actualUngrouped, expected) {
+			t.Errorf("%s: expected ungrouped students %v, got %v", testCase.name, expected, actualUngrouped)
+		}
+		expectedSet := map[string]bool{}
+		for _, netID := range testCase.expectedUngrouped {
+			expectedSet[netID] = true
+		}
+		for _, student := range roster {
+			if actual, expected := project.isUngrouped(student), expectedSet[student.NetID]; actual != expected {
+				t.Errorf("%s: expected %s to be ungrouped to be %v, got %v", testCase.name, student.NetID, expected, actual)
+			}
+		}
+	}
+}
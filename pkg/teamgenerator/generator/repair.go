@@ -0,0 +1,547 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewRepairer returns a Repairer that restores groups to the sizes a ClassGrouping created with the same
+// configuration would make
+func NewRepairer(optimalGroupSize int, preferSmallerGroups bool, opts ...Option) Repairer {
+	return &classGrouping{optimalGroupSize: optimalGroupSize, preferSmallerGroups: preferSmallerGroups, options: newOptions(opts)}
+}
+
+// Move describes a student who changed groups when a grouping was repaired
+type Move struct {
+	// Student is the student who moved
+	Student api.Student
+
+	// From is the index of the student's group in the existing grouping, or -1 if they joined the class
+	From int
+
+	// To is the index of the student's group in the repaired grouping, or -1 if they dropped the class
+	To int
+}
+
+// String describes the move, numbering groups from one
+func (m Move) String() string {
+	student := fmt.Sprintf("%s (%s)", m.Student.FullName, m.Student.NetID)
+	switch {
+	case m.From < 0:
+		return fmt.Sprintf("%s joined group %d", student, m.To+1)
+	case m.To < 0:
+		return fmt.Sprintf("%s dropped out of group %d", student, m.From+1)
+	default:
+		return fmt.Sprintf("%s moved from group %d to group %d", student, m.From+1, m.To+1)
+	}
+}
+
+// Renumbering describes a group that was kept but given a new number, as a group before it was dissolved
+type Renumbering struct {
+	// From is the index of the group in the existing grouping
+	From int
+
+	// To is the index of the group in the repaired grouping
+	To int
+}
+
+// String describes the renumbering, numbering groups from one
+func (r Renumbering) String() string {
+	return fmt.Sprintf("group %d is now group %d", r.From+1, r.To+1)
+}
+
+// Repair keeps every group that is still needed together, dissolving the smallest groups when there are too many
+// and removing the members with the most repeat collaborations from groups that are too large. Everyone left over,
+// including students who joined the class, fills the groups that are too small, preferring groups where they have
+// no prior collaborators. Students are still kept apart as the constraints require, and students who are pinned or
+// kept together stay in their group when they all still share it, or are placed together again when they don't;
+// if they can't be, a *ConstraintConflictError is returned. When the project has roles, students who stay in their
+// group keep their role. Kept groups keep their numbers, with new groups taking the places of dissolved ones; when
+// there are fewer groups than before, the last groups take the places left over and are renumbered.
+func (g *classGrouping) Repair(existing api.ProjectGrouping, students []api.Student, priorGroupings []api.ProjectGrouping) (api.ProjectGrouping, []Move, []Renumbering, error) {
+	// rules and pinned groups that name students who have left the class no longer apply
+	g = g.forRoster(students)
+	if err := g.checkConstraints(students, []string{existing.Name}); err != nil {
+		return api.ProjectGrouping{}, nil, nil, err
+	}
+
+	random, _ := g.newRandom()
+	roster, associativeRoster := g.newRoster(students, priorGroupings)
+
+	project := &Project{Name: existing.Name, Roles: g.projectRoles[existing.Name], lockedStudents: map[*Student]bool{}, separatedStudents: map[pair]bool{}}
+	constraintsForProject := newProjectConstraints(g.constraints, existing.Name)
+	for _, apart := range constraintsForProject.apart {
+		project.Separate(associativeRoster[apart.Student], associativeRoster[apart.Partner])
+	}
+
+	// keep everyone who is still in the class in their group, as long as it is for their section
+	var moves []Move
+	origin := map[*Student]int{}
+	existingRoles := map[*Student]string{}
+	var groups []*Group
+	groupOf := map[*Student]*Group{}
+	for i, existingGroup := range existing.Groups {
+		group := NewGroup(0)
+		group.Section = existingGroup.Section
+		for _, member := range existingGroup.Members {
+			student, onRoster := associativeRoster[member.NetID]
+			if !onRoster {
+				moves = append(moves, Move{Student: member, From: i, To: -1})
+				continue
+			}
+			if _, grouped := origin[student]; grouped {
+				continue
+			}
+			origin[student] = i
+			if len(group.members) == 0 && len(group.Section) == 0 {
+				// groupings made before the class had sections don't record them
+				group.Section = student.Section
+			}
+			if student.Section == group.Section {
+				group.AddMember(student)
+				groupOf[student] = group
+				existingRoles[student] = member.Role
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	// students who are pinned or kept together stay in their group if they all still share it, and are taken out
+	// of their groups to be placed together again otherwise
+	units := g.lockedUnits(existing.Name, constraintsForProject, associativeRoster)
+	unitOf := map[*Student]int{}
+	pinned := map[*Group]bool{}
+	pinnedSizes := map[string]int{}
+	var displaced []lockedUnit
+	for i, unit := range units {
+		home := groupOf[unit.members[0]]
+		intact := home != nil && !pinned[home]
+		for _, member := range unit.members {
+			project.Lock(member)
+			unitOf[member] = i
+			intact = intact && groupOf[member] == home
+		}
+		if unit.pinned {
+			pinnedSizes[unit.members[0].Section] += len(unit.members)
+		}
+
+		if !intact {
+			for _, member := range unit.members {
+				if group, grouped := groupOf[member]; grouped {
+					group.RemoveMember(member)
+					delete(groupOf, member)
+				}
+			}
+			if unit.pinned {
+				displaced = append(displaced, unit)
+			}
+			continue
+		}
+		if unit.pinned {
+			// pinned groups are complete, so everyone else in the group has to move
+			for _, member := range append([]*Student{}, home.members...) {
+				if !unit.contains(member) {
+					home.RemoveMember(member)
+					delete(groupOf, member)
+				}
+			}
+			home.DesiredSize = len(home.members)
+			pinned[home] = true
+		}
+	}
+
+	// determine which groups are kept and how large they should be, dissolving any that are not needed
+	dissolved := map[*Group]bool{}
+	for _, group := range groups {
+		if !pinned[group] {
+			dissolved[group] = true
+		}
+	}
+	sections, sectionSizes := sectionSizes(roster)
+	for _, section := range sections {
+		// pinned students are already grouped, so only the rest of the section needs to be sized
+		leftover := sectionSizes[section] - pinnedSizes[section]
+		if leftover == 0 {
+			continue
+		}
+		groupSizes, err := g.sizingFor(existing.Name).groupSizes(leftover)
+		if err != nil {
+			return api.ProjectGrouping{}, nil, nil, fmt.Errorf("could not size groups for %s: %v", existing.Name, err)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(groupSizes)))
+
+		var inSection []*Group
+		for _, group := range groups {
+			if group.Section == section && !pinned[group] {
+				inSection = append(inSection, group)
+			}
+		}
+		// matching the largest groups with the largest sizes moves the fewest students
+		sort.SliceStable(inSection, func(i, j int) bool {
+			return len(inSection[i].members) > len(inSection[j].members)
+		})
+		kept := inSection[:min(len(inSection), len(groupSizes))]
+
+		// groups of the same size can take any of the sizes without moving more students, so the larger sizes
+		// go to those that the most students who have to move can join without repeating a collaboration
+		keptMembers := map[*Student]bool{}
+		for _, group := range kept {
+			for _, member := range group.members {
+				keptMembers[member] = true
+			}
+		}
+		freshStudents := map[*Group]int{}
+		for _, group := range kept {
+			for _, student := range roster {
+				if !keptMembers[student] && project.CanJoin(group, student) && !group.ContainsCollaboratorsOf(student) {
+					freshStudents[group]++
+				}
+			}
+		}
+		sort.SliceStable(kept, func(i, j int) bool {
+			if len(kept[i].members) != len(kept[j].members) {
+				return len(kept[i].members) > len(kept[j].members)
+			}
+			return freshStudents[kept[i]] > freshStudents[kept[j]]
+		})
+
+		for i, group := range kept {
+			group.DesiredSize = groupSizes[i]
+			delete(dissolved, group)
+		}
+		for _, size := range groupSizes[len(kept):] {
+			group := NewGroup(size)
+			group.Section = section
+			groups = append(groups, group)
+		}
+	}
+
+	// pinned students who no longer share a group are pinned together in a new one
+	newlyPinned := map[*Group]bool{}
+	for _, unit := range displaced {
+		group := NewGroup(len(unit.members))
+		group.Section = unit.members[0].Section
+		for _, member := range unit.members {
+			group.AddMember(member)
+		}
+		groups = append(groups, group)
+		pinned[group], newlyPinned[group] = true, true
+	}
+
+	for _, group := range groups {
+		if dissolved[group] {
+			for _, member := range append([]*Student{}, group.members...) {
+				group.RemoveMember(member)
+			}
+			continue
+		}
+		for len(group.members) > group.DesiredSize {
+			if member := mostRepairedMember(group, project); member != nil {
+				group.RemoveMember(member)
+				continue
+			}
+			// only students who are kept together are left, so some of them have to be placed together elsewhere
+			for _, member := range units[unitOf[group.members[0]]].members {
+				group.RemoveMember(member)
+			}
+		}
+	}
+	var renumbered []Renumbering
+	project.Groups, renumbered = numberGroups(groups[:len(existing.Groups)], groups[len(existing.Groups):], dissolved)
+
+	move := func(student *Student, to int) {
+		delete(existingRoles, student)
+		from, wasGrouped := origin[student]
+		if !wasGrouped {
+			from = -1
+		}
+		moves = append(moves, Move{Student: student.ToAPIStudent(), From: from, To: to})
+	}
+	for i, group := range project.Groups {
+		if newlyPinned[group] {
+			for _, member := range group.members {
+				move(member, i)
+			}
+		}
+	}
+
+	grouped := map[*Student]bool{}
+	for _, group := range project.Groups {
+		for _, member := range group.members {
+			grouped[member] = true
+		}
+	}
+	for _, student := range roster {
+		if !grouped[student] {
+			project.MarkStudentUngrouped(student)
+		}
+	}
+
+	// students who are kept together and have to move are placed first, the largest sets first, where they fit; if
+	// they fit nowhere, the fewest students who aren't locked in a group move out of the way
+	for _, unit := range units {
+		if unit.pinned || !project.isUngrouped(unit.members[0]) {
+			continue
+		}
+		var fresh, stale []int
+		chosen, fewestLeaving := -1, 0
+		var leaving []*Student
+		for i, group := range project.Groups {
+			inTheWay, fits := makeRoom(project, group, unit.members)
+			if !fits {
+				continue
+			}
+			if len(inTheWay) > 0 {
+				if chosen < 0 || len(inTheWay) < fewestLeaving {
+					chosen, fewestLeaving, leaving = i, len(inTheWay), inTheWay
+				}
+				continue
+			}
+			collaborated := false
+			for _, member := range unit.members {
+				collaborated = collaborated || group.ContainsCollaboratorsOf(member)
+			}
+			if collaborated {
+				stale = append(stale, i)
+			} else {
+				fresh = append(fresh, i)
+			}
+		}
+
+		candidates := fresh
+		if len(candidates) == 0 {
+			candidates = stale
+		}
+		if len(candidates) > 0 {
+			chosen, leaving = candidates[random.Intn(len(candidates))], nil
+		}
+		if chosen < 0 {
+			return api.ProjectGrouping{}, nil, nil, &ConstraintConflictError{Conflicts: []string{fmt.Sprintf("for %s, no group has room for the students that rules %s keep together", project.Name, describeConstraints(unit.rules))}}
+		}
+
+		for _, student := range leaving {
+			project.Groups[chosen].RemoveMember(student)
+			project.MarkStudentUngrouped(student)
+		}
+		for _, member := range unit.members {
+			project.Groups[chosen].AddMember(member)
+			project.MarkStudentGrouped(member)
+			move(member, chosen)
+		}
+	}
+
+	// fill the groups that are too small with the students who have to move
+	for i, group := range project.Groups {
+		for !group.IsFull() {
+			var fresh, stale []*Student
+			for _, student := range project.UngroupedStudents {
+				if !project.CanJoin(group, student) {
+					continue
+				}
+				if group.ContainsCollaboratorsOf(student) {
+					stale = append(stale, student)
+				} else {
+					fresh = append(fresh, student)
+				}
+			}
+
+			candidates := fresh
+			if len(candidates) == 0 {
+				candidates = stale
+			}
+			if len(candidates) == 0 {
+				return api.ProjectGrouping{}, nil, nil, fmt.Errorf("no student can join group %d of %s", i+1, project.Name)
+			}
+
+			student := candidates[random.Intn(len(candidates))]
+			group.AddMember(student)
+			project.MarkStudentGrouped(student)
+			move(student, i)
+		}
+	}
+
+	// students who stayed in their group keep their role, and the roles that are left go to those who joined
+	if len(project.Roles) > 0 {
+		timesHeld := func(student *Student, role string) int {
+			return student.priorRoles[role]
+		}
+		for _, group := range project.Groups {
+			group.fillRoles(roleSlots(project.Roles, len(group.members)), timesHeld, existingRoles)
+		}
+	}
+
+	return project.ToAPIProjectGrouping(), moves, renumbered, nil
+}
+
+// numberGroups orders the repaired groups so that the existing groups that were kept keep their numbers. New groups
+// take the places of dissolved ones first, and are added at the end when there are none left. Places that are
+// still left over are taken by the last groups, which are renumbered.
+func numberGroups(existing, added []*Group, dissolved map[*Group]bool) ([]*Group, []Renumbering) {
+	var numbered []*Group
+	for _, group := range existing {
+		if dissolved[group] {
+			group = nil
+		}
+		numbered = append(numbered, group)
+	}
+	for i := range numbered {
+		if numbered[i] == nil && len(added) > 0 {
+			numbered[i], added = added[0], added[1:]
+		}
+	}
+	numbered = append(numbered, added...)
+
+	var renumbered []Renumbering
+	for i := 0; i < len(numbered); i++ {
+		if numbered[i] != nil {
+			continue
+		}
+		last := len(numbered) - 1
+		for last > i && numbered[last] == nil {
+			last--
+		}
+		if last == i {
+			numbered = numbered[:i]
+			break
+		}
+		numbered[i], numbered[last] = numbered[last], nil
+		renumbered = append(renumbered, Renumbering{From: last, To: i})
+		numbered = numbered[:last]
+	}
+	return numbered, renumbered
+}
+
+// mostRepairedMember finds the member of the group who has collaborated before with the most of its other members,
+// out of those who are not locked in it, or nil if everyone is
+func mostRepairedMember(group *Group, project *Project) *Student {
+	var chosen *Student
+	most := -1
+	for _, member := range group.members {
+		if project.IsLocked(member) {
+			continue
+		}
+		repairings := 0
+		for _, other := range group.members {
+			if other != member && member.Collaborations(other) > 1 {
+				repairings++
+			}
+		}
+		if repairings > most {
+			chosen, most = member, repairings
+		}
+	}
+	return chosen
+}
+
+// lockedUnit is a set of students that a repair has to keep in one group
+type lockedUnit struct {
+	members []*Student
+
+	// pinned units make up a whole group, while the rules are what keep the students in other units together
+	pinned bool
+	rules  []api.Constraint
+}
+
+// lockedUnits gathers the students pinned together and the students that rules keep together in the project,
+// with the pinned groups first
+func (g *classGrouping) lockedUnits(name string, constraintsForProject projectConstraints, associativeRoster map[string]*Student) []lockedUnit {
+	var units []lockedUnit
+	pinned := map[string]bool{}
+	for _, group := range g.pinnedGroups[name] {
+		unit := lockedUnit{pinned: true}
+		for _, member := range group.Members {
+			unit.members = append(unit.members, associativeRoster[member.NetID])
+			pinned[member.NetID] = true
+		}
+		units = append(units, unit)
+	}
+	for _, component := range constraintsForProject.components {
+		if pinned[component.netIDs[0]] {
+			// the students were pinned together already
+			continue
+		}
+		unit := lockedUnit{rules: component.rules}
+		for _, netID := range component.netIDs {
+			unit.members = append(unit.members, associativeRoster[netID])
+		}
+		units = append(units, unit)
+	}
+	return units
+}
+
+// contains determines if the student is in the unit
+func (u lockedUnit) contains(student *Student) bool {
+	for _, member := range u.members {
+		if member == student {
+			return true
+		}
+	}
+	return false
+}
+
+// makeRoom determines which members of the group would have to leave it for the students to join, and whether
+// they can join at all. Members locked in the group never leave, and members the students must be kept apart from
+// always do.
+func makeRoom(project *Project, group *Group, students []*Student) ([]*Student, bool) {
+	if group.Section != students[0].Section {
+		return nil, false
+	}
+
+	var leaving, staying []*Student
+	for _, member := range group.members {
+		separated := false
+		for _, student := range students {
+			separated = separated || project.separatedStudents[newPair(member, student)]
+		}
+		switch {
+		case separated && project.IsLocked(member):
+			return nil, false
+		case separated:
+			leaving = append(leaving, member)
+		case !project.IsLocked(member):
+			staying = append(staying, member)
+		}
+	}
+
+	// the members who joined the group last leave first
+	for len(group.members)-len(leaving)+len(students) > group.DesiredSize {
+		if len(staying) == 0 {
+			return nil, false
+		}
+		leaving, staying = append(leaving, staying[len(staying)-1]), staying[:len(staying)-1]
+	}
+	return leaving, true
+}
+
+// forRoster copies the configuration without the rules and pinned students that name anyone not on the roster
+func (g *classGrouping) forRoster(students []api.Student) *classGrouping {
+	onRoster := map[string]bool{}
+	for _, student := range students {
+		onRoster[student.NetID] = true
+	}
+
+	filtered := *g
+	filtered.constraints = nil
+	for _, constraint := range g.constraints {
+		if onRoster[constraint.Student] && onRoster[constraint.Partner] {
+			filtered.constraints = append(filtered.constraints, constraint)
+		}
+	}
+	filtered.pinnedGroups = map[string][]api.Group{}
+	for name, groups := range g.pinnedGroups {
+		for _, group := range groups {
+			var members []api.Student
+			for _, member := range group.Members {
+				if onRoster[member.NetID] {
+					members = append(members, member)
+				}
+			}
+			if len(members) > 0 {
+				filtered.pinnedGroups[name] = append(filtered.pinnedGroups[name], api.Group{Members: members})
+			}
+		}
+	}
+	return &filtered
+}
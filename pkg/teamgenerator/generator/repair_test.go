@@ -0,0 +1,313 @@
+package generator
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// testGrouping creates a project grouping with groups made up of the students at the given indices of the roster
+func testGrouping(roster []api.Student, groups ...[]int) api.ProjectGrouping {
+	grouping := api.ProjectGrouping{Name: "design"}
+	for _, indices := range groups {
+		var group api.Group
+		for _, index := range indices {
+			group.Members = append(group.Members, roster[index])
+		}
+		grouping.Groups = append(grouping.Groups, group)
+	}
+	return grouping
+}
+
+func TestRepair(t *testing.T) {
+	class := testRoster(14)
+	existing := testGrouping(class, []int{0, 1, 2}, []int{3, 4, 5}, []int{6, 7, 8}, []int{9, 10, 11})
+
+	var testCases = []struct {
+		name                string
+		roster              []api.Student
+		preferSmallerGroups bool
+		priors              []api.ProjectGrouping
+		expectedMoves       []string
+		expectedRenumbered  []string
+	}{
+		{
+			name:                "student drops",
+			roster:              append(append([]api.Student{}, class[:4]...), class[5:12]...),
+			preferSmallerGroups: true,
+			expectedMoves:       []string{"Student 4 (s4@duke.edu) dropped out of group 2"},
+		},
+		{
+			name:          "student joins",
+			roster:        class[:13],
+			priors:        []api.ProjectGrouping{testGrouping(class, []int{12, 0}, []int{12, 3}, []int{12, 6})},
+			expectedMoves: []string{"Student 12 (s12@duke.edu) joined group 4"},
+		},
+		{
+			name:   "group is dissolved",
+			roster: append(append([]api.Student{}, class[:4]...), class[6:12]...),
+			expectedMoves: []string{
+				"Student 4 (s4@duke.edu) dropped out of group 2",
+				"Student 5 (s5@duke.edu) dropped out of group 2",
+				"Student 3 (s3@duke.edu) moved from group 2 to group 1",
+			},
+			expectedRenumbered: []string{"group 4 is now group 2"},
+		},
+		{
+			name:   "group is refilled in its place",
+			roster: append(append([]api.Student{}, class[:4]...), class[6:]...),
+			expectedMoves: []string{
+				"Student 4 (s4@duke.edu) dropped out of group 2",
+				"Student 5 (s5@duke.edu) dropped out of group 2",
+				"Student 13 (s13@duke.edu) joined group 2",
+				"Student 12 (s12@duke.edu) joined group 2",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		repairer := NewRepairer(3, testCase.preferSmallerGroups, WithSeed(1))
+		grouping, moves, renumbered, err := repairer.Repair(existing, testCase.roster, testCase.priors)
+		if err != nil {
+			t.Errorf("%s: failed to repair grouping: %v", testCase.name, err)
+			continue
+		}
+		checkEveryStudentGroupedOnce(t, testCase.name, testCase.roster, api.ClassGrouping{Projects: []api.ProjectGrouping{grouping}})
+
+		var actualMoves []string
+		for _, move := range moves {
+			actualMoves = append(actualMoves, move.String())
+		}
+		if !reflect.DeepEqual(actualMoves, testCase.expectedMoves) {
+			t.Errorf("%s: did not make the correct moves:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedMoves, actualMoves)
+		}
+
+		var actualRenumbered []string
+		for _, renumbering := range renumbered {
+			actualRenumbered = append(actualRenumbered, renumbering.String())
+		}
+		if !reflect.DeepEqual(actualRenumbered, testCase.expectedRenumbered) {
+			t.Errorf("%s: did not renumber the correct groups:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedRenumbered, actualRenumbered)
+		}
+	}
+}
+
+func TestRepairKeepsLockedStudents(t *testing.T) {
+	class := testRoster(12)
+	together := func(student, partner int) api.Constraint {
+		return api.Constraint{Kind: api.MustBeTogether, Student: class[student].NetID, Partner: class[partner].NetID}
+	}
+	apart := func(student, partner int) api.Constraint {
+		return api.Constraint{Kind: api.MustBeApart, Student: class[student].NetID, Partner: class[partner].NetID}
+	}
+	pinned := func(members ...int) []api.ProjectGrouping {
+		return []api.ProjectGrouping{testGrouping(class, members)}
+	}
+
+	var testCases = []struct {
+		name             string
+		existing         api.ProjectGrouping
+		roster           []api.Student
+		groupSize        int
+		constraints      []api.Constraint
+		pinned           []api.ProjectGrouping
+		expectedTogether [][]int
+		expectedGroups   [][]int
+		expectedConflict bool
+	}{
+		{
+			name:             "students kept together stay together when their group is dissolved",
+			existing:         testGrouping(class, []int{0, 1, 2}, []int{3, 4, 5}, []int{6, 7, 8}, []int{9, 10, 11}),
+			roster:           append(append(append([]api.Student{}, class[:2]...), class[3:5]...), class[6:]...),
+			groupSize:        3,
+			constraints:      []api.Constraint{together(3, 4)},
+			expectedTogether: [][]int{{3, 4}},
+		},
+		{
+			name:             "students kept together leave a group that is too large together",
+			existing:         testGrouping(class, []int{0, 1, 2, 3}, []int{4, 5}),
+			roster:           class[:6],
+			groupSize:        3,
+			constraints:      []api.Constraint{together(0, 1), together(2, 3)},
+			expectedTogether: [][]int{{0, 1}, {2, 3}},
+		},
+		{
+			name:           "pinned students keep their group to themselves",
+			existing:       testGrouping(class, []int{0, 1, 2}, []int{3, 4, 5}, []int{6, 7, 8}),
+			roster:         class[:9],
+			groupSize:      3,
+			pinned:         pinned(0, 1),
+			expectedGroups: [][]int{{0, 1}},
+		},
+		{
+			name:           "pinned students who were split are pinned together again",
+			existing:       testGrouping(class, []int{0, 1, 2}, []int{3, 4, 5}),
+			roster:         class[:6],
+			groupSize:      3,
+			pinned:         pinned(0, 3),
+			expectedGroups: [][]int{{0, 3}},
+		},
+		{
+			name:             "students kept together who can't be placed without moving others kept together",
+			existing:         testGrouping(class, []int{0, 1, 2, 9}, []int{3, 4, 5, 10}, []int{6, 7, 8, 11}),
+			roster:           class,
+			groupSize:        4,
+			constraints:      []api.Constraint{together(0, 1), together(3, 4), together(6, 7), together(2, 5), apart(2, 0), apart(2, 3), apart(5, 6)},
+			expectedConflict: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		repairer := NewRepairer(testCase.groupSize, false, WithSeed(1), WithConstraints(testCase.constraints), WithPinnedGroups(testCase.pinned))
+		grouping, _, _, err := repairer.Repair(testCase.existing, testCase.roster, nil)
+		if testCase.expectedConflict {
+			if _, ok := err.(*ConstraintConflictError); !ok {
+				t.Errorf("%s: expected a constraint conflict, got %v", testCase.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: failed to repair grouping: %v", testCase.name, err)
+			continue
+		}
+		checkEveryStudentGroupedOnce(t, testCase.name, testCase.roster, api.ClassGrouping{Projects: []api.ProjectGrouping{grouping}})
+
+		groupOf := map[string]int{}
+		for i, group := range grouping.Groups {
+			for _, member := range group.Members {
+				groupOf[member.NetID] = i
+			}
+		}
+		for _, students := range testCase.expectedTogether {
+			for _, student := range students[1:] {
+				if groupOf[class[student].NetID] != groupOf[class[students[0]].NetID] {
+					t.Errorf("%s: expected students %v to share a group, got %v", testCase.name, students, grouping.Groups)
+				}
+			}
+		}
+		for _, students := range testCase.expectedGroups {
+			group := grouping.Groups[groupOf[class[students[0]].NetID]]
+			var members []int
+			for _, member := range group.Members {
+				for i, student := range class {
+					if student.NetID == member.NetID {
+						members = append(members, i)
+					}
+				}
+			}
+			sort.Ints(members)
+			if !reflect.DeepEqual(members, students) {
+				t.Errorf("%s: expected students %v to have a group to themselves, got %v", testCase.name, students, members)
+			}
+		}
+	}
+}
+
+func TestRepairKeepsRoles(t *testing.T) {
+	class := testRoster(7)
+	existing := testGrouping(class, []int{0, 1, 2}, []int{3, 4, 5})
+	for _, group := range existing.Groups {
+		for i, role := range []string{"manager", "builder", "presenter"} {
+			group.Members[i].Role = role
+		}
+	}
+	roster := append(append([]api.Student{}, class[:1]...), class[2:]...)
+
+	repairer := NewRepairer(3, false, WithSeed(1), WithProjectRoles([]api.ProjectRoles{{Name: "design", Roles: []string{"manager", "builder", "presenter"}}}))
+	grouping, _, _, err := repairer.Repair(existing, roster, nil)
+	if err != nil {
+		t.Fatalf("failed to repair grouping: %v", err)
+	}
+
+	actualRoles := map[string]string{}
+	for _, group := range grouping.Groups {
+		for _, member := range group.Members {
+			actualRoles[member.NetID] = member.Role
+		}
+	}
+	expectedRoles := map[string]string{
+		"s0@duke.edu": "manager", "s6@duke.edu": "builder", "s2@duke.edu": "presenter",
+		"s3@duke.edu": "manager", "s4@duke.edu": "builder", "s5@duke.edu": "presenter",
+	}
+	if !reflect.DeepEqual(actualRoles, expectedRoles) {
+		t.Errorf("expected students who stayed to keep their roles and the student who joined to fill in, wanted %v, got %v", expectedRoles, actualRoles)
+	}
+}
+
+func TestNumberGroups(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		existing           int
+		dissolved          []int
+		added              int
+		expectedOrder      []int
+		expectedRenumbered []Renumbering
+	}{
+		{
+			name:          "nothing changes",
+			existing:      3,
+			expectedOrder: []int{0, 1, 2},
+		},
+		{
+			name:          "new group takes the place of a dissolved one",
+			existing:      3,
+			dissolved:     []int{1},
+			added:         1,
+			expectedOrder: []int{0, 3, 2},
+		},
+		{
+			name:          "new groups are added at the end",
+			existing:      2,
+			added:         2,
+			expectedOrder: []int{0, 1, 2, 3},
+		},
+		{
+			name:               "last group takes the place of a dissolved one",
+			existing:           4,
+			dissolved:          []int{1},
+			expectedOrder:      []int{0, 3, 2},
+			expectedRenumbered: []Renumbering{{From: 3, To: 1}},
+		},
+		{
+			name:          "last group is dissolved",
+			existing:      4,
+			dissolved:     []int{3},
+			expectedOrder: []int{0, 1, 2},
+		},
+		{
+			name:               "dissolved groups before and at the end",
+			existing:           5,
+			dissolved:          []int{0, 4},
+			expectedOrder:      []int{3, 1, 2},
+			expectedRenumbered: []Renumbering{{From: 3, To: 0}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		var groups []*Group
+		index := map[*Group]int{}
+		for i := 0; i < testCase.existing+testCase.added; i++ {
+			group := NewGroup(3)
+			groups = append(groups, group)
+			index[group] = i
+		}
+		dissolved := map[*Group]bool{}
+		for _, i := range testCase.dissolved {
+			dissolved[groups[i]] = true
+		}
+
+		numbered, renumbered := numberGroups(groups[:testCase.existing], groups[testCase.existing:], dissolved)
+		var order []int
+		for _, group := range numbered {
+			order = append(order, index[group])
+		}
+		if !reflect.DeepEqual(order, testCase.expectedOrder) {
+			t.Errorf("%s: expected groups in order %v, got %v", testCase.name, testCase.expectedOrder, order)
+		}
+		if !reflect.DeepEqual(renumbered, testCase.expectedRenumbered) {
+			t.Errorf("%s: expected renumbering %v, got %v", testCase.name, testCase.expectedRenumbered, renumbered)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// maxExactRoleAssignment is the largest group for which the best assignment of roles is searched for exhaustively;
+// members of larger groups choose their roles one at a time
+const maxExactRoleAssignment = 16
+
+// assignRoles assigns roles to the members of every group in the projects that have roles. Projects are assigned in
+// order, and every group's roles go to the members who have had them the fewest times, counting prior groupings and
+// earlier projects, so that nobody repeats a role while one they haven't tried is available in their group.
+func assignRoles(projects []*Project) {
+	held := map[*Student]map[string]int{}
+	timesHeld := func(student *Student, role string) int {
+		return student.priorRoles[role] + held[student][role]
+	}
+
+	for _, project := range projects {
+		for _, group := range project.Groups {
+			group.roles = nil
+			if len(project.Roles) == 0 {
+				continue
+			}
+
+			group.fillRoles(roleSlots(project.Roles, len(group.members)), timesHeld, nil)
+			for member, role := range group.roles {
+				if held[member] == nil {
+					held[member] = map[string]int{}
+				}
+				held[member][role]++
+			}
+		}
+	}
+}
+
+// fillRoles gives every member of the group one of the roles in the slots. Members keep the roles they are given in
+// kept as long as a slot for it is open, and the rest of the slots go to the members who have had them the fewest times.
+func (g *Group) fillRoles(slots []string, timesHeld func(student *Student, role string) int, kept map[*Student]string) {
+	g.roles = map[*Student]string{}
+	open := append([]string{}, slots...)
+	var unassigned []*Student
+	for _, member := range g.members {
+		if role, ok := kept[member]; ok {
+			if i := slices.Index(open, role); i >= 0 {
+				g.roles[member] = role
+				open = slices.Delete(open, i, i+1)
+				continue
+			}
+		}
+		unassigned = append(unassigned, member)
+	}
+
+	costs := make([][]int, len(unassigned))
+	for i, member := range unassigned {
+		costs[i] = make([]int, len(open))
+		for j, role := range open {
+			costs[i][j] = timesHeld(member, role)
+		}
+	}
+	for i, slot := range cheapestAssignment(costs) {
+		g.roles[unassigned[i]] = open[slot]
+	}
+}
+
+// roleSlots lists the roles to fill in a group of the given size, sharing roles starting from the first when
+// there are more members than roles and leaving the last roles unfilled when there are fewer
+func roleSlots(roles []string, size int) []string {
+	slots := make([]string, size)
+	for i := range slots {
+		slots[i] = roles[i%len(roles)]
+	}
+	return slots
+}
+
+// cheapestAssignment determines which slot every member should fill so that the total cost is the lowest, given
+// the cost of every member filling every slot. When every assignment costs the same, members take the slots in order.
+func cheapestAssignment(costs [][]int) []int {
+	n := len(costs)
+	if n > maxExactRoleAssignment {
+		return greedyAssignment(costs)
+	}
+
+	// cheapest[filled] is the lowest cost of giving the first members the set of slots in filled, where the
+	// number of members is the number of slots in the set, and choice[filled] is the slot the last of them takes
+	cheapest := make([]int, 1<<n)
+	choice := make([]int, 1<<n)
+	for filled := 1; filled < 1<<n; filled++ {
+		member := bits.OnesCount(uint(filled)) - 1
+		cheapest[filled] = -1
+		for slot := 0; slot < n; slot++ {
+			if filled&(1<<slot) == 0 {
+				continue
+			}
+			cost := cheapest[filled&^(1<<slot)] + costs[member][slot]
+			if cheapest[filled] < 0 || cost <= cheapest[filled] {
+				cheapest[filled], choice[filled] = cost, slot
+			}
+		}
+	}
+
+	assignment := make([]int, n)
+	for filled, member := 1<<n-1, n-1; member >= 0; member-- {
+		assignment[member] = choice[filled]
+		filled &^= 1 << choice[filled]
+	}
+	return assignment
+}
+
+// greedyAssignment lets every member in turn take the cheapest slot that is left
+func greedyAssignment(costs [][]int) []int {
+	taken := map[int]bool{}
+	assignment := make([]int, len(costs))
+	for member := range costs {
+		best := -1
+		for slot := range costs[member] {
+			if !taken[slot] && (best < 0 || costs[member][slot] < costs[member][best]) {
+				best = slot
+			}
+		}
+		assignment[member] = best
+		taken[best] = true
+	}
+	return assignment
+}
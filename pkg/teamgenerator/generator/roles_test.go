@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssignRoles(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		roles         [][]string
+		priorRoles    map[int]string
+		projects      [][][]int
+		expectedRoles [][]map[string]string
+	}{
+		{
+			name:     "no roles",
+			roles:    [][]string{nil},
+			projects: [][][]int{{{0, 1}, {2, 3}}},
+			expectedRoles: [][]map[string]string{
+				{{"s0@duke.edu": "", "s1@duke.edu": ""}, {"s2@duke.edu": "", "s3@duke.edu": ""}},
+			},
+		},
+		{
+			name:     "roles rotate across projects",
+			roles:    [][]string{{"manager", "builder"}, {"manager", "builder"}},
+			projects: [][][]int{{{0, 1}, {2, 3}}, {{0, 1}, {2, 3}}},
+			expectedRoles: [][]map[string]string{
+				{{"s0@duke.edu": "manager", "s1@duke.edu": "builder"}, {"s2@duke.edu": "manager", "s3@duke.edu": "builder"}},
+				{{"s0@duke.edu": "builder", "s1@duke.edu": "manager"}, {"s2@duke.edu": "builder", "s3@duke.edu": "manager"}},
+			},
+		},
+		{
+			name:       "roles from prior groupings are not repeated",
+			roles:      [][]string{{"manager", "builder"}},
+			priorRoles: map[int]string{0: "manager", 3: "builder"},
+			projects:   [][][]int{{{0, 1}, {2, 3}}},
+			expectedRoles: [][]map[string]string{
+				{{"s0@duke.edu": "builder", "s1@duke.edu": "manager"}, {"s2@duke.edu": "builder", "s3@duke.edu": "manager"}},
+			},
+		},
+		{
+			name:     "roles shared in larger groups",
+			roles:    [][]string{{"manager", "builder"}},
+			projects: [][][]int{{{0, 1, 2}, {3}}},
+			expectedRoles: [][]map[string]string{
+				{{"s0@duke.edu": "manager", "s1@duke.edu": "builder", "s2@duke.edu": "manager"}, {"s3@duke.edu": "manager"}},
+			},
+		},
+		{
+			name:     "untried roles chosen before repeating in a smaller group",
+			roles:    [][]string{{"manager", "builder", "presenter"}, {"manager", "builder", "presenter"}},
+			projects: [][][]int{{{0, 1, 2}, {3}}, {{0, 3}, {1, 2}}},
+			expectedRoles: [][]map[string]string{
+				{{"s0@duke.edu": "manager", "s1@duke.edu": "builder", "s2@duke.edu": "presenter"}, {"s3@duke.edu": "manager"}},
+				{{"s0@duke.edu": "manager", "s3@duke.edu": "builder"}, {"s1@duke.edu": "manager", "s2@duke.edu": "builder"}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		var roster []*Student
+		for i, student := range testRoster(4) {
+			roster = append(roster, NewStudent(student))
+			if role, ok := testCase.priorRoles[i]; ok {
+				roster[i].priorRoles[role]++
+			}
+		}
+		indexRoster(roster)
+		var projects []*Project
+		for i, groups := range testCase.projects {
+			project := testProject(roster, groups...)
+			project.Roles = testCase.roles[i]
+			projects = append(projects, project)
+		}
+
+		assignRoles(projects)
+		for i, project := range projects {
+			var actualRoles []map[string]string
+			for _, group := range project.ToAPIProjectGrouping().Groups {
+				roles := map[string]string{}
+				for _, member := range group.Members {
+					roles[member.NetID] = member.Role
+				}
+				actualRoles = append(actualRoles, roles)
+			}
+			if expected := testCase.expectedRoles[i]; !reflect.DeepEqual(actualRoles, expected) {
+				t.Errorf("%s: expected roles %v for project %d, got %v", testCase.name, expected, i, actualRoles)
+			}
+		}
+	}
+}
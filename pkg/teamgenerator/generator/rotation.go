@@ -0,0 +1,31 @@
+package generator
+
+// NewSizeRotationScorer returns a scorer that counts the times students are put in an odd-sized group when they
+// have already been in one, either in a prior grouping or in an earlier project
+func NewSizeRotationScorer() Scorer {
+	return &sizeRotationScorer{}
+}
+
+type sizeRotationScorer struct{}
+
+// Score counts the places in odd-sized groups in the projects that were given to students who had already been in
+// an odd-sized group. Students who were in more than one in prior groupings are not counted again.
+func (s *sizeRotationScorer) Score(projects []*Project) float64 {
+	oddSizedGroups := map[*Student]int{}
+	for _, project := range projects {
+		for _, group := range project.Groups {
+			if project.IsOddSized(group) {
+				for _, member := range group.Members() {
+					oddSizedGroups[member]++
+				}
+			}
+		}
+	}
+
+	repeats := 0
+	for student, count := range oddSizedGroups {
+		total := student.priorOddSizedGroups + count
+		repeats += max(0, total-1) - max(0, student.priorOddSizedGroups-1)
+	}
+	return float64(repeats)
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUsualSize(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		sizes         []int
+		expectedSize  int
+		expectedFound bool
+	}{
+		{
+			name:          "no groups",
+			expectedFound: false,
+		},
+		{
+			name:          "every group the same size",
+			sizes:         []int{3, 3, 3},
+			expectedSize:  3,
+			expectedFound: true,
+		},
+		{
+			name:          "one larger group",
+			sizes:         []int{3, 3, 4},
+			expectedSize:  3,
+			expectedFound: true,
+		},
+		{
+			name:          "mostly smaller groups",
+			sizes:         []int{2, 2, 3},
+			expectedSize:  2,
+			expectedFound: true,
+		},
+		{
+			name:          "as many of each size",
+			sizes:         []int{3, 3, 4, 4},
+			expectedFound: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		size, found := usualSize(testCase.sizes)
+		if found != testCase.expectedFound {
+			t.Errorf("%s: expected finding a usual size to be %v, got %v", testCase.name, testCase.expectedFound, found)
+		}
+		if found && size != testCase.expectedSize {
+			t.Errorf("%s: expected usual size %d, got %d", testCase.name, testCase.expectedSize, size)
+		}
+	}
+}
+
+func TestSizeRotationScorer(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		priorOddSized []int
+		projects      [][][]int
+		expected      float64
+	}{
+		{
+			name:     "odd-sized groups given to different students",
+			projects: [][][]int{{{0, 1}, {2, 3}, {4}}, {{0, 4}, {1, 2}, {3}}},
+			expected: 0,
+		},
+		{
+			name:     "odd-sized group given to the same student",
+			projects: [][][]int{{{0, 1}, {2, 3}, {4}}, {{0, 1}, {2, 3}, {4}}},
+			expected: 1,
+		},
+		{
+			name:          "odd-sized group given to a student who had one in a prior",
+			priorOddSized: []int{0, 0, 0, 0, 1},
+			projects:      [][][]int{{{0, 1}, {2, 3}, {4}}},
+			expected:      1,
+		},
+		{
+			name:          "repeats in priors are not counted again",
+			priorOddSized: []int{0, 0, 0, 2, 0},
+			projects:      [][][]int{{{0, 1}, {2, 3}, {4}}},
+			expected:      0,
+		},
+		{
+			name:     "no usual size",
+			projects: [][][]int{{{0, 1}, {2, 3, 4}}, {{0, 1}, {2, 3, 4}}},
+			expected: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var roster []*Student
+		for i, student := range testRoster(5) {
+			roster = append(roster, NewStudent(student))
+			if i < len(testCase.priorOddSized) {
+				roster[i].priorOddSizedGroups = testCase.priorOddSized[i]
+			}
+		}
+		indexRoster(roster)
+		var projects []*Project
+		for _, groups := range testCase.projects {
+			projects = append(projects, testProject(roster, groups...))
+		}
+
+		if actual := NewSizeRotationScorer().Score(projects); actual != testCase.expected {
+			t.Errorf("%s: expected score %g, got %g", testCase.name, testCase.expected, actual)
+		}
+	}
+}
+
+func TestGenerateRotatesOddSizedGroups(t *testing.T) {
+	// sixteen students in groups of three leave one group of four in every project, so there are enough
+	// students for nobody to be in it twice without repeating a collaboration
+	roster := testRoster(16)
+	for seed := int64(0); seed < 10; seed++ {
+		generator := NewAnnealingClassGrouping(3, false, DefaultSchedule(), WithSeed(seed), WithSizeRotation(1))
+		grouping, err := generator.GenerateContext(context.Background(), roster, []string{"first", "second"}, Budget{Attempts: 1})
+		if !foundGrouping(grouping, err) {
+			t.Fatalf("seed %d: expected a grouping, got error %v", seed, err)
+		}
+		if grouping.Summary.Repairings > 0 {
+			t.Errorf("seed %d: expected no repairings, got %d", seed, grouping.Summary.Repairings)
+		}
+
+		oddSized := map[string]int{}
+		for _, project := range grouping.Projects {
+			for _, group := range project.Groups {
+				if len(group.Members) == 4 {
+					for _, member := range group.Members {
+						oddSized[member.NetID]++
+					}
+				}
+			}
+		}
+		for student, count := range oddSized {
+			if count > 1 {
+				t.Errorf("seed %d: expected %s to be in the group of four at most once, got %d", seed, student, count)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package generator
+
+// weightedScorer is a scorer registered with the weight its score carries in the objective
+type weightedScorer struct {
+	Scorer
+	weight float64
+}
+
+// NewRepairingScorer returns a scorer that counts the number of times students are grouped with someone they
+// have already collaborated with, either in a prior grouping or in an earlier project
+func NewRepairingScorer() Scorer {
+	return &repairingScorer{}
+}
+
+type repairingScorer struct{}
+
+// Score counts the repairings made in the projects. Repairings between students that happened before the projects,
+// like those in prior groupings, are not counted. Repeating a collaboration from a weighted prior grouping costs its
+// weight instead of one.
+func (s *repairingScorer) Score(projects []*Project) float64 {
+	var repairings float64
+	for pair, count := range pairCounts(projects) {
+		repairings += float64(count - 1)
+		if pair.student.Collaborations(pair.partner) > count {
+			// the first collaboration in the projects repeats a prior one
+			repairings += pair.student.PriorWeight(pair.partner)
+		}
+	}
+	return repairings
+}
+
+// countRepairings counts the repairings made in the projects, without weighting any of them
+func countRepairings(projects []*Project) int {
+	repairings := 0
+	for pair, count := range pairCounts(projects) {
+		total := pair.student.Collaborations(pair.partner)
+		repairings += max(0, total-1) - max(0, total-count-1)
+	}
+	return repairings
+}
+
+// pair is an unordered pair of students
+type pair struct {
+	student, partner *Student
+}
+
+// newPair orders the students so that the same two students always make the same pair
+func newPair(student, partner *Student) pair {
+	if partner.index < student.index {
+		student, partner = partner, student
+	}
+	return pair{student: student, partner: partner}
+}
+
+// pairCounts counts the number of times every pair of students are grouped together in the projects
+func pairCounts(projects []*Project) map[pair]int {
+	counts := map[pair]int{}
+	for _, project := range projects {
+		for _, group := range project.Groups {
+			members := group.Members()
+			for i := 0; i < len(members); i++ {
+				for j := i + 1; j < len(members); j++ {
+					counts[newPair(members[i], members[j])]++
+				}
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// testProject creates a project with groups made up of the students at the given indices of the roster
+func testProject(roster []*Student, groups ...[]int) *Project {
+	project := &Project{Name: "test"}
+	for _, indices := range groups {
+		group := NewGroup(len(indices))
+		for _, index := range indices {
+			group.AddMember(roster[index])
+		}
+		project.Groups = append(project.Groups, group)
+	}
+	return project
+}
+
+func TestRepairingScorer(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		priors             [][]int
+		projects           [][][]int
+		expectedRepairings float64
+	}{
+		{
+			name:               "no repeats",
+			projects:           [][][]int{{{0, 1}, {2, 3}}, {{0, 2}, {1, 3}}},
+			expectedRepairings: 0,
+		},
+		{
+			name:               "repeat within projects",
+			projects:           [][][]int{{{0, 1}, {2, 3}}, {{0, 1}, {2, 3}}},
+			expectedRepairings: 2,
+		},
+		{
+			name:               "repeat of a prior",
+			priors:             [][]int{{0, 1}},
+			projects:           [][][]int{{{0, 1}, {2, 3}}},
+			expectedRepairings: 1,
+		},
+		{
+			name:               "repeats between priors are not counted",
+			priors:             [][]int{{0, 1}, {0, 1}},
+			projects:           [][][]int{{{0, 2}, {1, 3}}},
+			expectedRepairings: 0,
+		},
+		{
+			name:               "partially filled groups",
+			priors:             [][]int{{0, 1, 2}},
+			projects:           [][][]int{{{0, 1, 2}, {3}}},
+			expectedRepairings: 3,
+		},
+	}
+
+	for _, testCase := range testCases {
+		var roster []*Student
+		for _, student := range testRoster(4) {
+			roster = append(roster, NewStudent(student))
+		}
+		indexRoster(roster)
+		testProject(roster, testCase.priors...)
+
+		var projects []*Project
+		for _, groups := range testCase.projects {
+			projects = append(projects, testProject(roster, groups...))
+		}
+
+		if actual, expected := NewRepairingScorer().Score(projects), testCase.expectedRepairings; actual != expected {
+			t.Errorf("%s: did not count repairings correctly, expected %g, got %g", testCase.name, expected, actual)
+		}
+	}
+}
+
+func TestObjectiveIsWeightedSum(t *testing.T) {
+	roster := []*Student{NewStudent(api.Student{NetID: "a"}), NewStudent(api.Student{NetID: "b"})}
+	indexRoster(roster)
+	projects := []*Project{testProject(roster, []int{0, 1}), testProject(roster, []int{0, 1})}
+
+	defaults := newOptions(nil)
+	if actual, expected := defaults.score(projects), 1.0; actual != expected {
+		t.Errorf("default objective did not count repairings, expected %g, got %g", expected, actual)
+	}
+
+	weighted := newOptions([]Option{WithScorer(NewRepairingScorer(), 2), WithScorer(NewRepairingScorer(), 0.5)})
+	if actual, expected := weighted.score(projects), 2.5; actual != expected {
+		t.Errorf("objective did not weight scores correctly, expected %g, got %g", expected, actual)
+	}
+}
+
+func TestWeightedPriors(t *testing.T) {
+	class := testRoster(4)
+	priors := []api.ProjectGrouping{
+		testGrouping(class, []int{0, 1}, []int{2, 3}),
+		testGrouping(class, []int{0, 2}, []int{1, 3}),
+		testGrouping(class, []int{0, 3}, []int{1, 2}),
+	}
+
+	var testCases = []struct {
+		name          string
+		options       []Option
+		expectedScore float64
+	}{
+		{
+			name:          "unweighted priors",
+			expectedScore: 3,
+		},
+		{
+			name:          "weighted priors",
+			options:       []Option{WithPriorWeights([]float64{0.25, 0.5, 2})},
+			expectedScore: 0.25 + 0.25 + 2,
+		},
+		{
+			name:          "decayed priors",
+			options:       []Option{WithPriorDecay(0.5)},
+			expectedScore: 0.25 + 0.25 + 1,
+		},
+		{
+			name:          "weights take precedence over decay",
+			options:       []Option{WithPriorWeights([]float64{0, 0}), WithPriorDecay(0.5)},
+			expectedScore: 0 + 0 + 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		o := newOptions(testCase.options)
+		roster, _ := o.newRoster(class, priors)
+		// repeats both pairs from the first prior and one from the last
+		projects := []*Project{testProject(roster, []int{0, 1}, []int{2, 3}), testProject(roster, []int{1, 2})}
+
+		if actual, expected := NewRepairingScorer().Score(projects), testCase.expectedScore; actual != expected {
+			t.Errorf("%s: did not weight repairings correctly, expected %g, got %g", testCase.name, expected, actual)
+		}
+		if actual, expected := countRepairings(projects), 3; actual != expected {
+			t.Errorf("%s: did not count repairings correctly, expected %d, got %d", testCase.name, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package generator
+
+import "github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+
+// bestGrouping keeps track of the best grouping found during a search
+type bestGrouping struct {
+	// grouping is the best grouping found so far
+	grouping api.ClassGrouping
+
+	// score is the value of the objective for the best grouping
+	score float64
+
+	// found determines if any grouping has been found yet
+	found bool
+
+	// options summarize the best grouping
+	options *options
+}
+
+// newBestGrouping starts to track the best grouping for a search using the given seed
+func newBestGrouping(seed int64, options *options) *bestGrouping {
+	return &bestGrouping{grouping: api.ClassGrouping{Seed: seed}, options: options}
+}
+
+// consider records the fully grouped projects as the best grouping if they score better than the best
+// grouping found so far, and determines if they did
+func (b *bestGrouping) consider(projects []*Project, score float64) bool {
+	if b.found && score >= b.score {
+		return false
+	}
+
+	b.found = true
+	b.score = score
+	b.grouping.Projects = nil
+	assignRoles(projects)
+	for _, project := range projects {
+		b.grouping.Projects = append(b.grouping.Projects, project.ToAPIProjectGrouping())
+	}
+	b.grouping.Summary = api.Summary{
+		Repairings: countRepairings(projects),
+		Score:      score,
+	}
+	b.options.summarize(projects, &b.grouping.Summary)
+	return true
+}
+
+// repairings returns the number of repairings in the best grouping, or -1 if none has been found
+func (b *bestGrouping) repairings() int {
+	if !b.found {
+		return -1
+	}
+	return b.grouping.Summary.Repairings
+}
+
+// result finishes the search, recording how it went in the summary of the best grouping
+func (b *bestGrouping) result(attempts int, err error) (api.ClassGrouping, error) {
+	b.grouping.Summary.Attempts = attempts
+	b.grouping.Summary.Partial = err != nil
+	if !b.found {
+		b.grouping.Summary.Repairings = -1
+	}
+	return b.grouping, err
+}
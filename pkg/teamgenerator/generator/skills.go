@@ -0,0 +1,183 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewSkillBalanceScorer returns a scorer that measures how far the average and spread of every group's skill
+// scores are from those of the class, for the named skills or for every skill if none are named
+func NewSkillBalanceScorer(skills []string) Scorer {
+	return &skillBalanceScorer{skills: skills}
+}
+
+type skillBalanceScorer struct {
+	skills []string
+}
+
+// Score sums the distance of every group's mean and standard deviation from the class's for every skill. Distances
+// are measured in units of the class's standard deviation, so that skills scored on different scales count the same.
+func (s *skillBalanceScorer) Score(projects []*Project) float64 {
+	var score float64
+	for _, project := range projects {
+		var students []*Student
+		for _, group := range project.Groups {
+			students = append(students, group.members...)
+		}
+
+		for _, skill := range skillNames(students, s.skills) {
+			class := newSkillStats(students, skill)
+			for _, group := range project.Groups {
+				if stats := newSkillStats(group.members, skill); stats.count > 0 {
+					score += (math.Abs(stats.mean-class.mean) + math.Abs(stats.spread-class.spread)) / class.scale()
+				}
+			}
+		}
+	}
+	return score
+}
+
+// skillStats describe the scores for a skill of the students who have one
+type skillStats struct {
+	// mean and spread are the average and standard deviation of the scores
+	mean, spread float64
+
+	// count is the number of students with a score
+	count int
+}
+
+func newSkillStats(students []*Student, skill string) skillStats {
+	var stats skillStats
+	var sum, sumOfSquares float64
+	for _, student := range students {
+		if score, ok := student.Skills[skill]; ok {
+			stats.count++
+			sum += score
+			sumOfSquares += score * score
+		}
+	}
+	if stats.count == 0 {
+		return stats
+	}
+
+	stats.mean = sum / float64(stats.count)
+	stats.spread = math.Sqrt(max(0, sumOfSquares/float64(stats.count)-stats.mean*stats.mean))
+	return stats
+}
+
+// scale is the unit distances from these scores are measured in, which is one when every score is the same
+func (s skillStats) scale() float64 {
+	if s.spread == 0 {
+		return 1
+	}
+	return s.spread
+}
+
+// skillNames returns the named skills, or the names of every skill any of the students have a score for, in order
+func skillNames(students []*Student, named []string) []string {
+	if len(named) > 0 {
+		return named
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	for _, student := range students {
+		for skill := range student.Skills {
+			if !seen[skill] {
+				seen[skill] = true
+				names = append(names, skill)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fillSnakeDraft assigns all ungrouped students in the project to groups for their section like a snake draft, so
+// that every group starts out with a similar mix of skills. Students are taken from the strongest to the weakest
+// across all of the skills, and the groups in every section take turns choosing, reversing their order every round.
+// Students are kept apart where they can be.
+func fillSnakeDraft(project *Project, skills []string, random *rand.Rand) {
+	ungrouped := append([]*Student{}, project.UngroupedStudents...)
+	random.Shuffle(len(ungrouped), func(i, j int) {
+		ungrouped[i], ungrouped[j] = ungrouped[j], ungrouped[i]
+	})
+
+	strength := map[*Student]float64{}
+	for _, skill := range skillNames(ungrouped, skills) {
+		class := newSkillStats(ungrouped, skill)
+		for _, student := range ungrouped {
+			if score, ok := student.Skills[skill]; ok {
+				strength[student] += (score - class.mean) / class.scale()
+			}
+		}
+	}
+	sort.SliceStable(ungrouped, func(i, j int) bool {
+		return strength[ungrouped[i]] > strength[ungrouped[j]]
+	})
+
+	groupsInSection := map[string][]*Group{}
+	for _, group := range project.Groups {
+		groupsInSection[group.Section] = append(groupsInSection[group.Section], group)
+	}
+	turns := map[string]int{}
+	for _, student := range ungrouped {
+		groups := groupsInSection[student.Section]
+		var chosen *Group
+		// every group gets a turn within two rounds, so there is no need to look further
+		for i := 0; i < 2*len(groups); i++ {
+			turn := turns[student.Section]
+			turns[student.Section]++
+			group := groups[turn%len(groups)]
+			if (turn/len(groups))%2 == 1 {
+				group = groups[len(groups)-1-turn%len(groups)]
+			}
+
+			if group.IsFull() {
+				continue
+			}
+			if project.CanJoin(group, student) {
+				chosen = group
+				break
+			}
+			if chosen == nil {
+				chosen = group
+			}
+		}
+		if chosen == nil {
+			return
+		}
+		chosen.AddMember(student)
+		project.MarkStudentGrouped(student)
+	}
+}
+
+// skillProfiles describes the skills of every group in the projects whose members have any skill scores
+func skillProfiles(projects []*Project) []api.SkillProfile {
+	var profiles []api.SkillProfile
+	for _, project := range projects {
+		for i, group := range project.Groups {
+			names := skillNames(group.members, nil)
+			if len(names) == 0 {
+				continue
+			}
+
+			profile := api.SkillProfile{Project: project.Name, Group: i + 1, Mean: map[string]float64{}, Spread: map[string]float64{}}
+			for _, skill := range names {
+				stats := newSkillStats(group.members, skill)
+				profile.Mean[skill] = roundScore(stats.mean)
+				profile.Spread[skill] = roundScore(stats.spread)
+			}
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// roundScore rounds the score to two decimal places for people to read
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
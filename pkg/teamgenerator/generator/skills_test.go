@@ -0,0 +1,142 @@
+package generator
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// skilledRoster creates a roster where every student has the given score for the skill
+func skilledRoster(skill string, scores ...float64) []*Student {
+	var roster []*Student
+	for i, student := range testRoster(len(scores)) {
+		student.Skills = map[string]float64{skill: scores[i]}
+		roster = append(roster, NewStudent(student))
+	}
+	indexRoster(roster)
+	return roster
+}
+
+func TestSkillBalanceScorer(t *testing.T) {
+	var testCases = []struct {
+		name             string
+		scores           []float64
+		groups           [][]int
+		expectedScore    float64
+		expectedProfiles []api.SkillProfile
+	}{
+		{
+			name:          "balanced groups",
+			scores:        []float64{1, 1, 5, 5},
+			groups:        [][]int{{0, 2}, {1, 3}},
+			expectedScore: 0,
+			expectedProfiles: []api.SkillProfile{
+				{Project: "test", Group: 1, Mean: map[string]float64{"CAD": 3}, Spread: map[string]float64{"CAD": 2}},
+				{Project: "test", Group: 2, Mean: map[string]float64{"CAD": 3}, Spread: map[string]float64{"CAD": 2}},
+			},
+		},
+		{
+			name:          "strong students together",
+			scores:        []float64{1, 1, 5, 5},
+			groups:        [][]int{{0, 1}, {2, 3}},
+			expectedScore: 4,
+			expectedProfiles: []api.SkillProfile{
+				{Project: "test", Group: 1, Mean: map[string]float64{"CAD": 1}, Spread: map[string]float64{"CAD": 0}},
+				{Project: "test", Group: 2, Mean: map[string]float64{"CAD": 5}, Spread: map[string]float64{"CAD": 0}},
+			},
+		},
+		{
+			name:          "everyone equally skilled",
+			scores:        []float64{3, 3, 3, 3},
+			groups:        [][]int{{0, 1}, {2, 3}},
+			expectedScore: 0,
+			expectedProfiles: []api.SkillProfile{
+				{Project: "test", Group: 1, Mean: map[string]float64{"CAD": 3}, Spread: map[string]float64{"CAD": 0}},
+				{Project: "test", Group: 2, Mean: map[string]float64{"CAD": 3}, Spread: map[string]float64{"CAD": 0}},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		projects := []*Project{testProject(skilledRoster("CAD", testCase.scores...), testCase.groups...)}
+
+		if actual := NewSkillBalanceScorer(nil).Score(projects); actual != testCase.expectedScore {
+			t.Errorf("%s: expected score %g, got %g", testCase.name, testCase.expectedScore, actual)
+		}
+		if actual := skillProfiles(projects); !reflect.DeepEqual(actual, testCase.expectedProfiles) {
+			t.Errorf("%s: expected skill profiles %v, got %v", testCase.name, testCase.expectedProfiles, actual)
+		}
+	}
+}
+
+func TestFillSnakeDraft(t *testing.T) {
+	roster := skilledRoster("CAD", 6, 5, 4, 3, 2, 1)
+	project, err := NewProject("design", roster, GroupSizing{OptimalSize: 3})
+	if err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	fillSnakeDraft(project, nil, rand.New(rand.NewSource(1)))
+
+	var actual [][]float64
+	for _, group := range project.Groups {
+		var scores []float64
+		for _, member := range group.Members() {
+			scores = append(scores, member.Skills["CAD"])
+		}
+		sort.Sort(sort.Reverse(sort.Float64Slice(scores)))
+		actual = append(actual, scores)
+	}
+	// the first group picks first, then the second group picks twice as the order reverses, and so on
+	if expected := [][]float64{{6, 3, 2}, {5, 4, 1}}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected groups to be drafted with scores %v, got %v", expected, actual)
+	}
+}
+
+func TestGenerateBalancesSkills(t *testing.T) {
+	// half of the class is strong at CAD, so a balanced group has at least one strong and one weak student
+	roster := testRoster(6)
+	for i := range roster {
+		roster[i].Skills = map[string]float64{"CAD": float64(10 * (i % 2))}
+	}
+
+	var testCases = []struct {
+		name      string
+		generator ClassGrouping
+		budget    Budget
+	}{
+		{
+			name:      "reshuffle",
+			generator: NewClassGrouping(3, false, WithSeed(1), WithSkillBalance(nil, 1)),
+		},
+		{
+			name:      "anneal",
+			generator: NewAnnealingClassGrouping(3, false, DefaultSchedule(), WithSeed(1), WithSkillBalance(nil, 1)),
+			budget:    Budget{Attempts: 1},
+		},
+	}
+
+	for _, testCase := range testCases {
+		grouping, err := testCase.generator.GenerateContext(context.Background(), roster, []string{"design"}, testCase.budget)
+		if !foundGrouping(grouping, err) {
+			t.Errorf("%s: expected a grouping, got error %v", testCase.name, err)
+			continue
+		}
+
+		for _, project := range grouping.Projects {
+			for i, group := range project.Groups {
+				scores := map[float64]bool{}
+				for _, member := range group.Members {
+					scores[member.Skills["CAD"]] = true
+				}
+				if len(scores) != 2 {
+					t.Errorf("%s: expected group %d for %s to mix strong and weak students, got %v", testCase.name, i, project.Name, group.Members)
+				}
+			}
+		}
+	}
+}
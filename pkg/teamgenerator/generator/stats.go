@@ -0,0 +1,59 @@
+package generator
+
+import "github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+
+// Stats describes how the students on the roster collaborate across the project groupings, so that a plan can be
+// checked before it is published. Students in the groupings who aren't on the roster are left out, and a student
+// listed twice in a group only counts once.
+func Stats(students []api.Student, groupings []api.ProjectGrouping) api.Stats {
+	var roster []*Student
+	associativeRoster := map[string]*Student{}
+	for _, student := range students {
+		internalStudent := NewStudent(student)
+		roster = append(roster, internalStudent)
+		associativeRoster[student.NetID] = internalStudent
+	}
+	indexRoster(roster)
+
+	var stats api.Stats
+	var projects []*Project
+	for _, grouping := range groupings {
+		project := &Project{Name: grouping.Name}
+		sizes := api.GroupSizes{Project: grouping.Name, Counts: map[int]int{}}
+		for _, apiGroup := range grouping.Groups {
+			group := NewGroup(len(apiGroup.Members))
+			for _, member := range apiGroup.Members {
+				if student, onRoster := associativeRoster[member.NetID]; onRoster && !group.Contains(student) {
+					group.AddMember(student)
+				}
+			}
+			project.Groups = append(project.Groups, group)
+			sizes.Counts[len(group.members)]++
+		}
+		projects = append(projects, project)
+		stats.GroupSizes = append(stats.GroupSizes, sizes)
+	}
+
+	_, sectionSizes := sectionSizes(roster)
+	for _, student := range roster {
+		row, partners := make([]int, len(roster)), 0
+		for _, classmate := range roster {
+			row[classmate.index] = student.Collaborations(classmate)
+			if classmate != student && student.HasCollaboratedWith(classmate) {
+				partners++
+			}
+		}
+		stats.Students = append(stats.Students, student.NetID)
+		stats.Collaborations = append(stats.Collaborations, row)
+		stats.Coverage = append(stats.Coverage, api.PartnerCoverage{Student: student.NetID, Partners: partners, Classmates: sectionSizes[student.Section] - 1})
+	}
+
+	stats.Repairings = countRepairings(projects)
+	for student, repeats := range studentRepeats(projects) {
+		if stats.Repeats == nil {
+			stats.Repeats = map[string]int{}
+		}
+		stats.Repeats[student.NetID] = repeats
+	}
+	return stats
+}
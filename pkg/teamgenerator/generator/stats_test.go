@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestStats(t *testing.T) {
+	roster := testRoster(5)
+	final := testGrouping(roster, []int{0, 1, 4}, []int{2, 3, 3})
+	final.Name = "final"
+	final.Groups[1].Members = append(final.Groups[1].Members, api.Student{NetID: "stranger@duke.edu"})
+
+	var testCases = []struct {
+		name          string
+		groupings     []api.ProjectGrouping
+		expectedStats api.Stats
+	}{
+		{
+			name: "no groupings",
+			expectedStats: api.Stats{
+				Students:       []string{"s0@duke.edu", "s1@duke.edu", "s2@duke.edu", "s3@duke.edu", "s4@duke.edu"},
+				Collaborations: [][]int{{0, 0, 0, 0, 0}, {0, 0, 0, 0, 0}, {0, 0, 0, 0, 0}, {0, 0, 0, 0, 0}, {0, 0, 0, 0, 0}},
+				Coverage: []api.PartnerCoverage{
+					{Student: "s0@duke.edu", Classmates: 4},
+					{Student: "s1@duke.edu", Classmates: 4},
+					{Student: "s2@duke.edu", Classmates: 4},
+					{Student: "s3@duke.edu", Classmates: 4},
+					{Student: "s4@duke.edu", Classmates: 4},
+				},
+			},
+		},
+		{
+			name:      "repeated collaborations",
+			groupings: []api.ProjectGrouping{testGrouping(roster, []int{0, 1}, []int{2, 3, 4}), final},
+			expectedStats: api.Stats{
+				Students: []string{"s0@duke.edu", "s1@duke.edu", "s2@duke.edu", "s3@duke.edu", "s4@duke.edu"},
+				Collaborations: [][]int{
+					{0, 2, 0, 0, 1},
+					{2, 0, 0, 0, 1},
+					{0, 0, 0, 2, 1},
+					{0, 0, 2, 0, 1},
+					{1, 1, 1, 1, 0},
+				},
+				Repairings: 2,
+				Repeats:    map[string]int{"s0@duke.edu": 1, "s1@duke.edu": 1, "s2@duke.edu": 1, "s3@duke.edu": 1},
+				Coverage: []api.PartnerCoverage{
+					{Student: "s0@duke.edu", Partners: 2, Classmates: 4},
+					{Student: "s1@duke.edu", Partners: 2, Classmates: 4},
+					{Student: "s2@duke.edu", Partners: 2, Classmates: 4},
+					{Student: "s3@duke.edu", Partners: 2, Classmates: 4},
+					{Student: "s4@duke.edu", Partners: 4, Classmates: 4},
+				},
+				GroupSizes: []api.GroupSizes{
+					{Project: "design", Counts: map[int]int{2: 1, 3: 1}},
+					{Project: "final", Counts: map[int]int{2: 1, 3: 1}},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		if actual, expected := Stats(roster, testCase.groupings), testCase.expectedStats; !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected stats %+v, got %+v", testCase.name, expected, actual)
+		}
+	}
+}
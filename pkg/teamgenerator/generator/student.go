@@ -6,15 +6,55 @@ import "github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator
 type Student struct {
 	api.Student
 
-	// collaborators are other students this student has already collaborated with
-	collaborators map[*Student]int
+	// index is the position of this student in the class, which identifies them in the collaboration counts
+	index int
+
+	// collaborators counts the times this student has collaborated with every student in the class, by their
+	// index. The counts for the whole class are one matrix, so each student's counts are a row of it.
+	collaborators []int32
+
+	// collaborated has a bit set for every student in the class this student has collaborated with, by their
+	// index, which is far smaller than the counts to check
+	collaborated bitset
+
+	// priorWeights are how much repeating a collaboration from a prior grouping costs, for the other
+	// students this student collaborated with in weighted prior groupings
+	priorWeights map[*Student]float64
+
+	// priorGroupings are the names of the prior groupings this student collaborated with other students in,
+	// which are only recorded to explain groupings
+	priorGroupings map[*Student][]string
+
+	// priorOddSizedGroups is the number of groups in prior groupings this student was in that were
+	// not of the usual size
+	priorOddSizedGroups int
+
+	// priorRoles are the number of times this student had every role in prior groupings
+	priorRoles map[string]int
+
+	// free are the hours of the week this student is free to meet, or nil if they didn't say
+	free map[api.TimeSlot]bool
 }
 
-// NewStudent creates a new student for the serializable student object
+// NewStudent creates a new student for the serializable student object. The student can't collaborate with
+// anyone until their class has been indexed.
 func NewStudent(student api.Student) *Student {
 	return &Student{
-		Student:       student,
-		collaborators: map[*Student]int{},
+		Student:      student,
+		priorWeights: map[*Student]float64{},
+		priorRoles:   map[string]int{},
+	}
+}
+
+// indexRoster numbers the students in the class and sets up the matrix counting their collaborations, clearing
+// any collaborations recorded before
+func indexRoster(roster []*Student) {
+	size, words := len(roster), bitsetWords(len(roster))
+	counts, bits := make([]int32, size*size), make(bitset, size*words)
+	for i, student := range roster {
+		student.index = i
+		student.collaborators = counts[i*size : (i+1)*size : (i+1)*size]
+		student.collaborated = bits[i*words : (i+1)*words : (i+1)*words]
 	}
 }
 
@@ -26,36 +66,68 @@ func (s *Student) ToAPIStudent() api.Student {
 // HasCollaboratedWith determines if this student has collaborated with another student.
 // Students are assumed to be uniquely identifiable by their NetID.
 func (s *Student) HasCollaboratedWith(student *Student) bool {
-	return s.collaborators[student] > 0
+	return s.collaborated.has(student.index)
 }
 
-// Collaborate marks the two students as having collaborated with each other and increments
-// the counter of re-pairings if one occurred as the result of this action
-func Collaborate(student, partner *Student) {
-	student.collaborators[partner]++
-	partner.collaborators[student]++
+// Collaborations determines how many times this student has collaborated with another student
+func (s *Student) Collaborations(student *Student) int {
+	return int(s.collaborators[student.index])
+}
 
-	if student.collaborators[partner] > 1 {
-		netRepairings++
+// PriorWeight determines how much repeating a prior collaboration with the other student costs,
+// which is one unless the prior grouping was weighted
+func (s *Student) PriorWeight(student *Student) float64 {
+	if weight, ok := s.priorWeights[student]; ok {
+		return weight
 	}
+	return 1
 }
 
-// Uncollaborate removes all records of collaboration between the students, if any existed,
-// and decrements the counter of re-pairings if one was removed as a result of this action
-func Uncollaborate(student, partner *Student) {
-	student.collaborators[partner]--
-	if student.collaborators[partner] < 1 {
-		delete(student.collaborators, partner)
+// recordPriorWeight records the weight of a prior grouping the students collaborated in, keeping
+// the largest weight if they collaborated more than once
+func recordPriorWeight(student, partner *Student, weight float64) {
+	if existing, ok := student.priorWeights[partner]; !ok || weight > existing {
+		student.priorWeights[partner] = weight
+		partner.priorWeights[student] = weight
 	}
+}
 
-	partner.collaborators[student]--
-	if partner.collaborators[student] < 1 {
-		delete(partner.collaborators, student)
+// recordPriorGrouping records the name of a prior grouping the students collaborated in
+func recordPriorGrouping(student, partner *Student, name string) {
+	if student.priorGroupings == nil {
+		student.priorGroupings = map[*Student][]string{}
+	}
+	if partner.priorGroupings == nil {
+		partner.priorGroupings = map[*Student][]string{}
 	}
+	student.priorGroupings[partner] = append(student.priorGroupings[partner], name)
+	partner.priorGroupings[student] = append(partner.priorGroupings[student], name)
+}
+
+// Collaborate marks the two students as having collaborated with each other and determines
+// if a re-pairing occurred as the result of this action
+func Collaborate(student, partner *Student) bool {
+	student.collaborators[partner.index]++
+	partner.collaborators[student.index]++
+	student.collaborated.set(partner.index)
+	partner.collaborated.set(student.index)
 
-	if student.collaborators[partner] > 0 {
-		netRepairings--
+	return student.collaborators[partner.index] > 1
+}
+
+// Uncollaborate removes all records of collaboration between the students, if any existed,
+// and determines if a re-pairing was removed as a result of this action
+func Uncollaborate(student, partner *Student) bool {
+	if student.collaborators[partner.index] > 0 {
+		student.collaborators[partner.index]--
+		partner.collaborators[student.index]--
 	}
+	if student.collaborators[partner.index] == 0 {
+		student.collaborated.clear(partner.index)
+		partner.collaborated.clear(student.index)
+	}
+
+	return student.collaborators[partner.index] > 0
 }
 
 // Equals determines if two student objects are the same. We assume that netIDs are uniquely identifying
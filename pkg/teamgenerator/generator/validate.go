@@ -0,0 +1,237 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewValidator returns a Validator that checks groupings against the group sizes, constraints and pinned groups
+// configured by the options, as a ClassGrouping with the same options would have generated them
+func NewValidator(optimalGroupSize int, preferSmallerGroups bool, opts ...Option) Validator {
+	return &classGrouping{optimalGroupSize: optimalGroupSize, preferSmallerGroups: preferSmallerGroups, options: newOptions(opts)}
+}
+
+const (
+	// DuplicateMember is the kind of violation where a student is in more than one group for a project
+	DuplicateMember = "duplicate member"
+
+	// MissingStudent is the kind of violation where a student on the roster isn't in any group for a project
+	MissingStudent = "missing student"
+
+	// UnknownStudent is the kind of violation where a group holds a student who isn't on the roster
+	UnknownStudent = "unknown student"
+
+	// WrongGroupSize is the kind of violation where a group doesn't have one of the sizes the project's groups
+	// should have
+	WrongGroupSize = "wrong group size"
+
+	// MixedSections is the kind of violation where a group holds students from more than one section
+	MixedSections = "mixed sections"
+
+	// BrokenConstraint is the kind of violation where students who must be together are not, students who must
+	// be apart are not, or a pinned group was split up
+	BrokenConstraint = "broken constraint"
+
+	// WrongSummary is the kind of violation where the summary of a grouping doesn't match its groups
+	WrongSummary = "wrong summary"
+)
+
+// Violation describes a way in which a grouping breaks the rules it should follow
+type Violation struct {
+	// Kind is the kind of rule that is broken
+	Kind string
+
+	// Project is the name of the project whose grouping breaks the rule, or empty for the grouping as a whole
+	Project string
+
+	// Group is the index of the group that breaks the rule in the project, or -1 if it's not about one group
+	Group int
+
+	// Student is the NetID of the student the violation is about, or empty if it's not about one student
+	Student string
+
+	// Detail describes how the rule is broken
+	Detail string
+}
+
+func (v Violation) String() string {
+	location := "grouping"
+	switch {
+	case len(v.Project) > 0 && v.Group >= 0:
+		location = fmt.Sprintf("%s group %d", v.Project, v.Group+1)
+	case len(v.Project) > 0:
+		location = v.Project
+	}
+	return fmt.Sprintf("%s: %s: %s", location, v.Kind, v.Detail)
+}
+
+// Validate checks every project in the class grouping against the roster, returning every violation found in the
+// order of the projects and their groups. If the grouping was made by a search, the repairings in its summary are
+// also checked against the roster and the prior groupings.
+func (g *classGrouping) Validate(students []api.Student, grouping api.ClassGrouping, priorGroupings []api.ProjectGrouping) []Violation {
+	roster, associativeRoster := g.newRoster(students, priorGroupings)
+
+	var violations []Violation
+	var projects []*Project
+	for _, projectGrouping := range grouping.Projects {
+		project, projectViolations := g.validateProject(projectGrouping, roster, associativeRoster)
+		violations = append(violations, projectViolations...)
+		projects = append(projects, project)
+	}
+
+	if grouping.Summary.Attempts > 0 {
+		if actual, reported := countRepairings(projects), grouping.Summary.Repairings; actual != reported {
+			violations = append(violations, Violation{
+				Kind:   WrongSummary,
+				Group:  -1,
+				Detail: fmt.Sprintf("the summary reports %d repairings, but the groups make %d", reported, actual),
+			})
+		}
+	}
+	return violations
+}
+
+// validateProject checks the groups for one project, returning the violations found along with the project made up
+// of the students on the roster in every group, each counted in the first group they are in
+func (g *classGrouping) validateProject(projectGrouping api.ProjectGrouping, roster []*Student, associativeRoster map[string]*Student) (*Project, []Violation) {
+	name := projectGrouping.Name
+	var violations []Violation
+	violate := func(kind string, group int, student, detail string, args ...interface{}) {
+		violations = append(violations, Violation{Kind: kind, Project: name, Group: group, Student: student, Detail: fmt.Sprintf(detail, args...)})
+	}
+
+	project := &Project{Name: name, lockedStudents: map[*Student]bool{}, separatedStudents: map[pair]bool{}}
+	groupOf := map[*Student]int{}
+	for i, apiGroup := range projectGrouping.Groups {
+		group := NewGroup(len(apiGroup.Members))
+		group.Section = apiGroup.Section
+		for _, member := range apiGroup.Members {
+			student, onRoster := associativeRoster[member.NetID]
+			if !onRoster {
+				violate(UnknownStudent, i, member.NetID, "%s is not on the roster", member.NetID)
+				continue
+			}
+			if first, grouped := groupOf[student]; grouped {
+				if first == i {
+					violate(DuplicateMember, i, member.NetID, "%s is in the group more than once", member.NetID)
+				} else {
+					violate(DuplicateMember, i, member.NetID, "%s is also in group %d", member.NetID, first+1)
+				}
+				continue
+			}
+			groupOf[student] = i
+			if len(group.members) == 0 && len(group.Section) == 0 {
+				group.Section = student.Section
+			}
+			if student.Section != group.Section {
+				violate(MixedSections, i, member.NetID, "%s is in %s, but the group is for %s", member.NetID, describeSection(student.Section), describeSection(group.Section))
+			}
+			group.AddMember(student)
+		}
+		project.Groups = append(project.Groups, group)
+	}
+
+	for _, student := range roster {
+		if _, grouped := groupOf[student]; !grouped {
+			violate(MissingStudent, -1, student.NetID, "%s is not in any group", student.NetID)
+		}
+	}
+
+	violations = append(violations, g.validateSizes(projectGrouping, roster, associativeRoster)...)
+
+	together := func(student, partner string) (bool, bool) {
+		first, firstGrouped := groupOf[associativeRoster[student]]
+		second, secondGrouped := groupOf[associativeRoster[partner]]
+		return first == second, firstGrouped && secondGrouped
+	}
+	for _, constraint := range g.constraints {
+		if !appliesTo(constraint.Projects, name) {
+			continue
+		}
+		// students who are missing from the grouping are already reported
+		if shared, grouped := together(constraint.Student, constraint.Partner); grouped && shared != (constraint.Kind == api.MustBeTogether) {
+			violate(BrokenConstraint, groupOf[associativeRoster[constraint.Student]], constraint.Student, "the rule %s is broken", describeConstraint(constraint))
+		}
+	}
+	for _, pinned := range g.pinnedGroups[name] {
+		if len(pinned.Members) == 0 {
+			continue
+		}
+		first := pinned.Members[0].NetID
+		for _, member := range pinned.Members[1:] {
+			if shared, grouped := together(first, member.NetID); grouped && !shared {
+				violate(BrokenConstraint, groupOf[associativeRoster[member.NetID]], member.NetID, "%s was pinned to a group with %s", member.NetID, first)
+			}
+		}
+	}
+
+	return project, violations
+}
+
+// validateSizes checks that the groups in every section have the sizes that the project's groups should have for
+// the students in the section, taking the sizes of any pinned groups as they are
+func (g *classGrouping) validateSizes(projectGrouping api.ProjectGrouping, roster []*Student, associativeRoster map[string]*Student) []Violation {
+	pinnedSizes, pinnedStudents := map[string][]int{}, map[string]int{}
+	for _, pinned := range g.pinnedGroups[projectGrouping.Name] {
+		size, section := 0, ""
+		for _, member := range pinned.Members {
+			if student, onRoster := associativeRoster[member.NetID]; onRoster {
+				size, section = size+1, student.Section
+			}
+		}
+		if size > 0 {
+			pinnedSizes[section] = append(pinnedSizes[section], size)
+			pinnedStudents[section] += size
+		}
+	}
+
+	var violations []Violation
+	sections, sectionSizes := sectionSizes(roster)
+	expectedSizes := map[string][]int{}
+	for _, section := range sections {
+		sizes, err := g.sizingFor(projectGrouping.Name).groupSizes(sectionSizes[section] - pinnedStudents[section])
+		if err != nil {
+			violations = append(violations, Violation{Kind: WrongGroupSize, Project: projectGrouping.Name, Group: -1, Detail: fmt.Sprintf("groups for %s can't be sized: %v", describeSection(section), err)})
+			continue
+		}
+		expectedSizes[section] = append(pinnedSizes[section], sizes...)
+	}
+
+	for i, group := range projectGrouping.Groups {
+		section := group.Section
+		for _, member := range group.Members {
+			if student, onRoster := associativeRoster[member.NetID]; onRoster && len(section) == 0 {
+				section = student.Section
+			}
+		}
+
+		expected, size := expectedSizes[section], len(group.Members)
+		if matched := indexOf(expected, size); matched >= 0 {
+			expectedSizes[section] = append(expected[:matched:matched], expected[matched+1:]...)
+			continue
+		}
+		sort.Ints(expected)
+		violations = append(violations, Violation{Kind: WrongGroupSize, Project: projectGrouping.Name, Group: i, Detail: fmt.Sprintf("the group has %d members, but the groups left for %s should have %v", size, describeSection(section), expected)})
+	}
+	return violations
+}
+
+// indexOf finds the index of the first occurrence of the value in the list, or -1 if it doesn't occur
+func indexOf(values []int, value int) int {
+	for i, candidate := range values {
+		if candidate == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// describeSection names the section for messages, as rosters without sections put everyone in the same one
+func describeSection(section string) string {
+	if len(section) == 0 {
+		return "the class"
+	}
+	return fmt.Sprintf("section %s", section)
+}
@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestValidate(t *testing.T) {
+	roster := testRoster(6)
+	withStranger := testGrouping(roster, []int{0, 1, 2}, []int{3, 4, 5})
+	withStranger.Groups[1].Members = append(withStranger.Groups[1].Members, api.Student{NetID: "stranger@duke.edu"})
+
+	var testCases = []struct {
+		name               string
+		grouping           api.ClassGrouping
+		priors             []api.ProjectGrouping
+		opts               []Option
+		expectedViolations []Violation
+	}{
+		{
+			name:     "valid grouping",
+			grouping: api.ClassGrouping{Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 1, 2}, []int{3, 4, 5})}},
+		},
+		{
+			name:     "student in two groups",
+			grouping: api.ClassGrouping{Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 1, 2}, []int{2, 3, 4})}},
+			expectedViolations: []Violation{
+				{Kind: DuplicateMember, Project: "design", Group: 1, Student: "s2@duke.edu"},
+				{Kind: MissingStudent, Project: "design", Group: -1, Student: "s5@duke.edu"},
+			},
+		},
+		{
+			name:     "student twice in one group",
+			grouping: api.ClassGrouping{Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 1, 2}, []int{3, 4, 5, 5})}},
+			expectedViolations: []Violation{
+				{Kind: DuplicateMember, Project: "design", Group: 1, Student: "s5@duke.edu"},
+				{Kind: WrongGroupSize, Project: "design", Group: 1},
+			},
+		},
+		{
+			name:     "student not on the roster",
+			grouping: api.ClassGrouping{Projects: []api.ProjectGrouping{withStranger}},
+			expectedViolations: []Violation{
+				{Kind: UnknownStudent, Project: "design", Group: 1, Student: "stranger@duke.edu"},
+				{Kind: WrongGroupSize, Project: "design", Group: 1},
+			},
+		},
+		{
+			name:     "groups of the wrong sizes",
+			grouping: api.ClassGrouping{Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 1}, []int{2, 3, 4, 5})}},
+			expectedViolations: []Violation{
+				{Kind: WrongGroupSize, Project: "design", Group: 0},
+				{Kind: WrongGroupSize, Project: "design", Group: 1},
+			},
+		},
+		{
+			name:     "students that must be apart share a group",
+			grouping: api.ClassGrouping{Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 1, 2}, []int{3, 4, 5})}},
+			opts: []Option{WithConstraints([]api.Constraint{
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s1@duke.edu"},
+				{Kind: api.MustBeApart, Student: "s0@duke.edu", Partner: "s3@duke.edu"},
+				{Kind: api.MustBeTogether, Student: "s4@duke.edu", Partner: "s5@duke.edu"},
+				{Kind: api.MustBeTogether, Student: "s2@duke.edu", Partner: "s3@duke.edu", Projects: []string{"final"}},
+			})},
+			expectedViolations: []Violation{
+				{Kind: BrokenConstraint, Project: "design", Group: 0, Student: "s0@duke.edu"},
+			},
+		},
+		{
+			name:     "pinned group kept together",
+			grouping: api.ClassGrouping{Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 1}, []int{2, 3, 4, 5})}},
+			opts:     []Option{WithPinnedGroups([]api.ProjectGrouping{testGrouping(roster, []int{0, 1})})},
+		},
+		{
+			name:     "pinned group split up",
+			grouping: api.ClassGrouping{Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 2, 3}, []int{1, 4, 5})}},
+			opts:     []Option{WithPinnedGroups([]api.ProjectGrouping{testGrouping(roster, []int{0, 1})})},
+			expectedViolations: []Violation{
+				{Kind: WrongGroupSize, Project: "design", Group: 0},
+				{Kind: WrongGroupSize, Project: "design", Group: 1},
+				{Kind: BrokenConstraint, Project: "design", Group: 1, Student: "s1@duke.edu"},
+			},
+		},
+		{
+			name: "summary matches the repairings",
+			grouping: api.ClassGrouping{
+				Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 1, 3}, []int{2, 4, 5})},
+				Summary:  api.Summary{Attempts: 1, Repairings: 2},
+			},
+			priors: []api.ProjectGrouping{testGrouping(roster, []int{0, 1, 2}, []int{3, 4, 5})},
+		},
+		{
+			name: "summary misses the repairings",
+			grouping: api.ClassGrouping{
+				Projects: []api.ProjectGrouping{testGrouping(roster, []int{0, 1, 3}, []int{2, 4, 5})},
+				Summary:  api.Summary{Attempts: 1},
+			},
+			priors: []api.ProjectGrouping{testGrouping(roster, []int{0, 1, 2}, []int{3, 4, 5})},
+			expectedViolations: []Violation{
+				{Kind: WrongSummary, Group: -1},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		actualViolations := NewValidator(3, false, testCase.opts...).Validate(roster, testCase.grouping, testCase.priors)
+		for i := range actualViolations {
+			if len(actualViolations[i].Detail) == 0 {
+				t.Errorf("%s: expected violation %d to be described", testCase.name, i)
+			}
+			actualViolations[i].Detail = ""
+		}
+		if expected := testCase.expectedViolations; !reflect.DeepEqual(actualViolations, expected) {
+			t.Errorf("%s: expected violations %v, got %v", testCase.name, expected, actualViolations)
+		}
+	}
+}
+
+func TestValidateGeneratedGroupings(t *testing.T) {
+	roster := sectionedRoster(map[string]int{"01": 7, "02": 5})
+	constraints := []api.Constraint{
+		{Kind: api.MustBeApart, Student: roster[0].NetID, Partner: roster[1].NetID},
+		{Kind: api.MustBeTogether, Student: roster[2].NetID, Partner: roster[3].NetID},
+	}
+	opts := []Option{WithConstraints(constraints), WithSeed(1)}
+
+	for _, strategy := range []ClassGrouping{
+		NewClassGrouping(3, false, opts...),
+		NewAnnealingClassGrouping(3, false, DefaultSchedule(), opts...),
+	} {
+		grouping := strategy.Generate(roster, []string{"first", "second", "third"})
+		if violations := NewValidator(3, false, opts...).Validate(roster, grouping, nil); len(violations) > 0 {
+			t.Errorf("expected a generated grouping to be valid, got %v", violations)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewCSVAvailability returns a new parser that can parse a CSV file into the hours students are free to meet
+func NewCSVAvailability() Availability {
+	return &csvAvailability{}
+}
+
+type csvAvailability struct{}
+
+// Parse parses availability from a CSV weekly grid, like those exported by scheduling polls, with a header
+// row naming the hour every column is for and one student per record after it. This format is as follows:
+// Student ID, Day HH:00[, Day HH:00...]
+// [a-z0-9]+@duke.edu,(1|0|x|)(,(1|0|x|))*
+// Days are written like Mon or Monday. A student is free in an hour when its column is 1, x, y or yes and
+// busy when it is 0, n, no or empty.
+func (a *csvAvailability) Parse(inputFile string) ([]api.Availability, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %v", inputFile, err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %v", inputFile, err)
+	}
+
+	if len(records) == 0 || !isHeader(records[0]) {
+		return nil, fmt.Errorf("expected the CSV availability file %q to start with a header row naming the hour of every column", inputFile)
+	}
+
+	var slots []api.TimeSlot
+	for _, label := range records[0][1:] {
+		slot, err := parseTimeSlot(label)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+
+	availability := []api.Availability{}
+	for _, record := range records[1:] {
+		studentAvailability, err := parseAvailability(slots, record)
+		if err != nil {
+			return nil, err
+		}
+
+		availability = append(availability, studentAvailability)
+	}
+
+	return availability, nil
+}
+
+// parseTimeSlot parses the label of a column in the weekly grid, like "Mon 09:00" or "Monday 9:00"
+func parseTimeSlot(label string) (api.TimeSlot, error) {
+	malformed := fmt.Errorf("found malformed hour %q in the availability header, expected a day and the start of an hour like Mon 09:00", label)
+	day, clock, found := strings.Cut(strings.TrimSpace(label), " ")
+	hour, minutes, _ := strings.Cut(strings.TrimSpace(clock), ":")
+	value, err := strconv.Atoi(hour)
+	if !found || err != nil || value < 0 || value > 23 || (len(minutes) > 0 && minutes != "00") {
+		return api.TimeSlot{}, malformed
+	}
+
+	for _, name := range api.Days {
+		if len(day) >= len(name) && strings.EqualFold(day[:len(name)], name) {
+			return api.TimeSlot{Day: name, Hour: value}, nil
+		}
+	}
+	return api.TimeSlot{}, malformed
+}
+
+// parseAvailability parses a record that marks whether the student is free in every one of the slots
+func parseAvailability(slots []api.TimeSlot, record []string) (api.Availability, error) {
+	if len(record) != len(slots)+1 {
+		return api.Availability{}, fmt.Errorf("expected all records in CSV availability file to contain %d columns, record %q contained %d", len(slots)+1, record, len(record))
+	}
+
+	availability := api.Availability{Student: strings.TrimSpace(record[0])}
+	if len(availability.Student) == 0 {
+		return api.Availability{}, fmt.Errorf("found availability %q without a student", record)
+	}
+
+	for i, column := range record[1:] {
+		switch strings.ToLower(strings.TrimSpace(column)) {
+		case "1", "x", "y", "yes":
+			availability.Free = append(availability.Free, slots[i])
+		case "0", "n", "no", "":
+		default:
+			return api.Availability{}, fmt.Errorf("found malformed availability %q for %s, expected 1 when free and 0 when busy", column, availability.Student)
+		}
+	}
+
+	return availability, nil
+}
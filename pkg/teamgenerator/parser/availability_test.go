@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestParseTimeSlot(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		label         string
+		expectedSlot  api.TimeSlot
+		expectedError error
+	}{
+		{
+			name:         "short day",
+			label:        "Mon 09:00",
+			expectedSlot: api.TimeSlot{Day: "Mon", Hour: 9},
+		},
+		{
+			name:         "full day without minutes",
+			label:        " thursday 14",
+			expectedSlot: api.TimeSlot{Day: "Thu", Hour: 14},
+		},
+		{
+			name:          "not on the hour",
+			label:         "Mon 09:30",
+			expectedError: errors.New(`found malformed hour "Mon 09:30" in the availability header, expected a day and the start of an hour like Mon 09:00`),
+		},
+		{
+			name:          "unknown day",
+			label:         "Someday 09:00",
+			expectedError: errors.New(`found malformed hour "Someday 09:00" in the availability header, expected a day and the start of an hour like Mon 09:00`),
+		},
+		{
+			name:          "no hour",
+			label:         "Mon",
+			expectedError: errors.New(`found malformed hour "Mon" in the availability header, expected a day and the start of an hour like Mon 09:00`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		actualSlot, actualError := parseTimeSlot(testCase.label)
+
+		if !reflect.DeepEqual(actualSlot, testCase.expectedSlot) {
+			t.Errorf("%s: correct slot not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedSlot, actualSlot)
+		}
+
+		if !reflect.DeepEqual(actualError, testCase.expectedError) {
+			t.Errorf("%s: correct error not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedError, actualError)
+		}
+	}
+}
+
+func TestParseAvailability(t *testing.T) {
+	slots := []api.TimeSlot{{Day: "Mon", Hour: 9}, {Day: "Mon", Hour: 10}, {Day: "Tue", Hour: 9}}
+
+	var testCases = []struct {
+		name                 string
+		record               []string
+		expectedAvailability api.Availability
+		expectedError        error
+	}{
+		{
+			name:                 "free and busy hours",
+			record:               []string{"s1@duke.edu", "1", "0", "x"},
+			expectedAvailability: api.Availability{Student: "s1@duke.edu", Free: []api.TimeSlot{{Day: "Mon", Hour: 9}, {Day: "Tue", Hour: 9}}},
+		},
+		{
+			name:                 "never free",
+			record:               []string{"s1@duke.edu", "", "no", "0"},
+			expectedAvailability: api.Availability{Student: "s1@duke.edu"},
+		},
+		{
+			name:          "missing columns",
+			record:        []string{"s1@duke.edu", "1"},
+			expectedError: errors.New(`expected all records in CSV availability file to contain 4 columns, record ["s1@duke.edu" "1"] contained 2`),
+		},
+		{
+			name:          "malformed mark",
+			record:        []string{"s1@duke.edu", "1", "maybe", "0"},
+			expectedError: errors.New(`found malformed availability "maybe" for s1@duke.edu, expected 1 when free and 0 when busy`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		actualAvailability, actualError := parseAvailability(slots, testCase.record)
+
+		if !reflect.DeepEqual(actualAvailability, testCase.expectedAvailability) {
+			t.Errorf("%s: correct availability not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedAvailability, actualAvailability)
+		}
+
+		if !reflect.DeepEqual(actualError, testCase.expectedError) {
+			t.Errorf("%s: correct error not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedError, actualError)
+		}
+	}
+}
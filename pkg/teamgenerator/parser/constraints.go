@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewCSVConstraints returns a new parser that can parse a CSV file into a list of constraints
+func NewCSVConstraints() Constraints {
+	return &csvConstraints{}
+}
+
+type csvConstraints struct{}
+
+// Parse parses constraints from a CSV file with one rule per record. This format is as follows:
+// Kind, Student ID, Student ID[, Project Name...]
+// (together|apart),[a-z0-9]+@duke.edu,[a-z0-9]+@duke.edu(,.+)*
+// A rule with no project names applies to all projects.
+func (c *csvConstraints) Parse(inputFile string) ([]api.Constraint, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %v", inputFile, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	// rules apply to different numbers of projects, so records will have different lengths
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %v", inputFile, err)
+	}
+
+	constraints := []api.Constraint{}
+	for _, record := range records {
+		constraint, err := parseConstraint(record)
+		if err != nil {
+			return nil, err
+		}
+
+		constraints = append(constraints, constraint)
+	}
+
+	return constraints, nil
+}
+
+func parseConstraint(record []string) (api.Constraint, error) {
+	if len(record) < 3 {
+		return api.Constraint{}, fmt.Errorf("expected all records in CSV constraints file to contain at least three columns, record %q contained %d", record, len(record))
+	}
+
+	kind := strings.ToLower(strings.TrimSpace(record[0]))
+	if kind != api.MustBeTogether && kind != api.MustBeApart {
+		return api.Constraint{}, fmt.Errorf("found unknown kind of constraint %q, expected %q or %q", record[0], api.MustBeTogether, api.MustBeApart)
+	}
+
+	student, partner := strings.TrimSpace(record[1]), strings.TrimSpace(record[2])
+	if student == partner {
+		return api.Constraint{}, fmt.Errorf("found constraint %q about a student and themselves", record)
+	}
+
+	var projects []string
+	for _, project := range record[3:] {
+		if project = strings.TrimSpace(project); len(project) > 0 {
+			projects = append(projects, project)
+		}
+	}
+
+	return api.Constraint{
+		Kind:     kind,
+		Student:  student,
+		Partner:  partner,
+		Projects: projects,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestParseConstraint(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		record             []string
+		expectedConstraint api.Constraint
+		expectedError      error
+	}{
+		{
+			name:               "rule for all projects",
+			record:             []string{"apart", "abc123@duke.edu", "def456@duke.edu"},
+			expectedConstraint: api.Constraint{Kind: api.MustBeApart, Student: "abc123@duke.edu", Partner: "def456@duke.edu"},
+			expectedError:      nil,
+		},
+		{
+			name:               "rule for named projects",
+			record:             []string{"Together", " abc123@duke.edu", "def456@duke.edu ", "design", "final"},
+			expectedConstraint: api.Constraint{Kind: api.MustBeTogether, Student: "abc123@duke.edu", Partner: "def456@duke.edu", Projects: []string{"design", "final"}},
+			expectedError:      nil,
+		},
+		{
+			name:               "unknown kind",
+			record:             []string{"near", "abc123@duke.edu", "def456@duke.edu"},
+			expectedConstraint: api.Constraint{},
+			expectedError:      errors.New(`found unknown kind of constraint "near", expected "together" or "apart"`),
+		},
+		{
+			name:               "rule about one student",
+			record:             []string{"apart", "abc123@duke.edu", "abc123@duke.edu"},
+			expectedConstraint: api.Constraint{},
+			expectedError:      fmt.Errorf("found constraint %q about a student and themselves", []string{"apart", "abc123@duke.edu", "abc123@duke.edu"}),
+		},
+		{
+			name:               "missing partner",
+			record:             []string{"apart", "abc123@duke.edu"},
+			expectedConstraint: api.Constraint{},
+			expectedError:      fmt.Errorf("expected all records in CSV constraints file to contain at least three columns, record %q contained %d", []string{"apart", "abc123@duke.edu"}, 2),
+		},
+	}
+
+	for _, testCase := range testCases {
+		actualConstraint, actualError := parseConstraint(testCase.record)
+
+		if !reflect.DeepEqual(actualConstraint, testCase.expectedConstraint) {
+			t.Errorf("%s: correct constraint not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedConstraint, actualConstraint)
+		}
+
+		if !reflect.DeepEqual(actualError, testCase.expectedError) {
+			t.Errorf("%s: correct error not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedError, actualError)
+		}
+	}
+}
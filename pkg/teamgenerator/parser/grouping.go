@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewJSONClassGrouping returns a parser for groupings saved as JSON. Either a class grouping or a single project
+// grouping may be given, and any lines teamgenerator prints before the JSON are skipped.
+func NewJSONClassGrouping() ClassGrouping {
+	return &jsonClassGrouping{}
+}
+
+type jsonClassGrouping struct{}
+
+// Parse decodes the class grouping, or the project grouping as a class grouping with just that project, from the
+// input file
+func (p *jsonClassGrouping) Parse(inputFile string) (api.ClassGrouping, error) {
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return api.ClassGrouping{}, fmt.Errorf("failed to open %q: %v", inputFile, err)
+	}
+
+	grouping, err := parseClassGrouping(data)
+	if err != nil {
+		return grouping, fmt.Errorf("failed to decode JSON from %q: %v", inputFile, err)
+	}
+	return grouping, nil
+}
+
+// parseClassGrouping decodes a class grouping or a project grouping from the first line that starts a JSON object
+func parseClassGrouping(data []byte) (api.ClassGrouping, error) {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("{")) {
+			data = bytes.Join(lines[i:], nil)
+			break
+		}
+	}
+
+	var contents struct {
+		api.ClassGrouping
+		api.ProjectGrouping
+	}
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return api.ClassGrouping{}, err
+	}
+
+	switch {
+	case len(contents.Projects) > 0:
+		return contents.ClassGrouping, nil
+	case len(contents.Groups) > 0:
+		return api.ClassGrouping{Projects: []api.ProjectGrouping{contents.ProjectGrouping}}, nil
+	default:
+		return api.ClassGrouping{}, errors.New("found no projects or groups")
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestParseClassGrouping(t *testing.T) {
+	design := api.ProjectGrouping{Name: "design", Groups: []api.Group{{Members: []api.Student{{FullName: "Jane Doe", NetID: "jd1@duke.edu"}}}}}
+
+	var testCases = []struct {
+		name             string
+		data             string
+		expectedGrouping api.ClassGrouping
+		expectedError    error
+	}{
+		{
+			name:             "class grouping",
+			data:             `{"projects":[{"name":"design","groups":[{"students":[{"name":"Jane Doe","netID":"jd1@duke.edu"}]}]}],"seed":5,"summary":{"repairings":1,"attempts":2}}`,
+			expectedGrouping: api.ClassGrouping{Projects: []api.ProjectGrouping{design}, Seed: 5, Summary: api.Summary{Repairings: 1, Attempts: 2}},
+		},
+		{
+			name:             "project grouping",
+			data:             `{"name":"design","groups":[{"students":[{"name":"Jane Doe","netID":"jd1@duke.edu"}]}]}`,
+			expectedGrouping: api.ClassGrouping{Projects: []api.ProjectGrouping{design}},
+		},
+		{
+			name: "output with progress lines",
+			data: `generating teams for the following projects: [design]
+generated teams using seed 5
+{"projects":[{"name":"design","groups":[{"students":[{"name":"Jane Doe","netID":"jd1@duke.edu"}]}]}],"seed":5,"summary":{"repairings":0}}
+`,
+			expectedGrouping: api.ClassGrouping{Projects: []api.ProjectGrouping{design}, Seed: 5},
+		},
+		{
+			name:          "no groupings",
+			data:          `{"seed":5}`,
+			expectedError: errors.New("found no projects or groups"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		grouping, err := parseClassGrouping([]byte(testCase.data))
+		if expected, actual := testCase.expectedError, err; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected error %v, got %v", testCase.name, expected, actual)
+		}
+		if expected, actual := testCase.expectedGrouping, grouping; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected grouping %+v, got %+v", testCase.name, expected, actual)
+		}
+	}
+}
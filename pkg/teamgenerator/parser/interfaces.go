@@ -13,3 +13,39 @@ type Project interface {
 	// Parse parses a project grouping from a file
 	Parse(inputFile string) (project api.ProjectGrouping, err error)
 }
+
+// ClassGrouping knows how to parse a class grouping from a file
+type ClassGrouping interface {
+	// Parse parses a class grouping from a file
+	Parse(inputFile string) (grouping api.ClassGrouping, err error)
+}
+
+// Constraints knows how to parse constraints on groupings from a file
+type Constraints interface {
+	// Parse parses constraints on groupings from a file
+	Parse(inputFile string) (constraints []api.Constraint, err error)
+}
+
+// ProjectSizes knows how to parse the sizes of groups for projects from a file
+type ProjectSizes interface {
+	// Parse parses the sizes of groups for projects from a file
+	Parse(inputFile string) (sizes []api.ProjectSize, err error)
+}
+
+// ProjectRoles knows how to parse the roles in groups for projects from a file
+type ProjectRoles interface {
+	// Parse parses the roles in groups for projects from a file
+	Parse(inputFile string) (roles []api.ProjectRoles, err error)
+}
+
+// Availability knows how to parse the hours students are free to meet from a file
+type Availability interface {
+	// Parse parses the hours students are free to meet from a file
+	Parse(inputFile string) (availability []api.Availability, err error)
+}
+
+// Preferences knows how to parse students' preferences about who they work with from a file
+type Preferences interface {
+	// Parse parses students' preferences about who they work with from a file
+	Parse(inputFile string) (preferences []api.Preference, err error)
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewCSVPreferences returns a new parser that can parse a CSV file into a list of preferences
+func NewCSVPreferences() Preferences {
+	return &csvPreferences{}
+}
+
+type csvPreferences struct{}
+
+// Parse parses preferences from a CSV file with one student's survey answers per record. This format is as follows:
+// Student ID, Preferred Student IDs, Avoided Student IDs[, Project Name...]
+// [a-z0-9]+@duke.edu,([a-z0-9]+@duke.edu( [a-z0-9]+@duke.edu)*)?,([a-z0-9]+@duke.edu( [a-z0-9]+@duke.edu)*)?(,.+)*
+// Student IDs in a list are separated by spaces or semicolons. Preferences with no project names apply to all projects.
+func (p *csvPreferences) Parse(inputFile string) ([]api.Preference, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %v", inputFile, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	// preferences apply to different numbers of projects, so records will have different lengths
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %v", inputFile, err)
+	}
+
+	preferences := []api.Preference{}
+	for _, record := range records {
+		preference, err := parsePreference(record)
+		if err != nil {
+			return nil, err
+		}
+
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, nil
+}
+
+func parsePreference(record []string) (api.Preference, error) {
+	if len(record) < 3 {
+		return api.Preference{}, fmt.Errorf("expected all records in CSV preferences file to contain at least three columns, record %q contained %d", record, len(record))
+	}
+
+	student := strings.TrimSpace(record[0])
+	if len(student) == 0 {
+		return api.Preference{}, fmt.Errorf("found preferences %q without a student", record)
+	}
+
+	preferred, avoided := parseNetIDs(record[1]), parseNetIDs(record[2])
+	for _, netID := range append(append([]string{}, preferred...), avoided...) {
+		if netID == student {
+			return api.Preference{}, fmt.Errorf("found preferences %q about a student and themselves", record)
+		}
+	}
+
+	var projects []string
+	for _, project := range record[3:] {
+		if project = strings.TrimSpace(project); len(project) > 0 {
+			projects = append(projects, project)
+		}
+	}
+
+	return api.Preference{
+		Student:   student,
+		Preferred: preferred,
+		Avoided:   avoided,
+		Projects:  projects,
+	}, nil
+}
+
+// parseNetIDs splits a list of NetIDs separated by spaces or semicolons
+func parseNetIDs(list string) []string {
+	var netIDs []string
+	for _, netID := range strings.FieldsFunc(list, func(r rune) bool { return r == ';' || r == ' ' || r == '\t' }) {
+		netIDs = append(netIDs, netID)
+	}
+	return netIDs
+}
@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestParsePreference(t *testing.T) {
+	var testCases = []struct {
+		name               string
+		record             []string
+		expectedPreference api.Preference
+		expectedError      error
+	}{
+		{
+			name:               "preferred and avoided students",
+			record:             []string{"abc123@duke.edu", "def456@duke.edu; ghi789@duke.edu", "jkl012@duke.edu"},
+			expectedPreference: api.Preference{Student: "abc123@duke.edu", Preferred: []string{"def456@duke.edu", "ghi789@duke.edu"}, Avoided: []string{"jkl012@duke.edu"}},
+		},
+		{
+			name:               "no answers for named projects",
+			record:             []string{" abc123@duke.edu ", "", "", "final"},
+			expectedPreference: api.Preference{Student: "abc123@duke.edu", Projects: []string{"final"}},
+		},
+		{
+			name:          "too few columns",
+			record:        []string{"abc123@duke.edu", "def456@duke.edu"},
+			expectedError: errors.New(`expected all records in CSV preferences file to contain at least three columns, record ["abc123@duke.edu" "def456@duke.edu"] contained 2`),
+		},
+		{
+			name:          "preference about themselves",
+			record:        []string{"abc123@duke.edu", "", "abc123@duke.edu"},
+			expectedError: errors.New(`found preferences ["abc123@duke.edu" "" "abc123@duke.edu"] about a student and themselves`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		actualPreference, actualError := parsePreference(testCase.record)
+
+		if !reflect.DeepEqual(actualPreference, testCase.expectedPreference) {
+			t.Errorf("%s: correct preference not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedPreference, actualPreference)
+		}
+
+		if !reflect.DeepEqual(actualError, testCase.expectedError) {
+			t.Errorf("%s: correct error not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedError, actualError)
+		}
+	}
+}
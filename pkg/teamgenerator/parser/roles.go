@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewCSVProjectRoles returns a new parser that can parse a CSV file into the roles in groups for projects
+func NewCSVProjectRoles() ProjectRoles {
+	return &csvProjectRoles{}
+}
+
+type csvProjectRoles struct{}
+
+// Parse parses the roles in groups from a CSV file with one project per record. This format is as follows:
+// Project Name, Role[, Role...]
+// .+,.+(,.+)*
+// Groups fill the roles in the order they are listed.
+func (r *csvProjectRoles) Parse(inputFile string) ([]api.ProjectRoles, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %v", inputFile, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %v", inputFile, err)
+	}
+
+	roles := []api.ProjectRoles{}
+	for _, record := range records {
+		projectRoles, err := parseProjectRoles(record)
+		if err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, projectRoles)
+	}
+
+	return roles, nil
+}
+
+func parseProjectRoles(record []string) (api.ProjectRoles, error) {
+	if len(record) < 2 {
+		return api.ProjectRoles{}, fmt.Errorf("expected all records in CSV roles file to contain a project and at least one role, record %q contained %d columns", record, len(record))
+	}
+
+	projectRoles := api.ProjectRoles{Name: strings.TrimSpace(record[0])}
+	if len(projectRoles.Name) == 0 {
+		return api.ProjectRoles{}, fmt.Errorf("found roles %q without a project name", record[1:])
+	}
+
+	seen := map[string]bool{}
+	for _, column := range record[1:] {
+		role := strings.TrimSpace(column)
+		if len(role) == 0 {
+			continue
+		}
+		if seen[role] {
+			return api.ProjectRoles{}, fmt.Errorf("found role %q more than once for %s", role, projectRoles.Name)
+		}
+		seen[role] = true
+		projectRoles.Roles = append(projectRoles.Roles, role)
+	}
+	if len(projectRoles.Roles) == 0 {
+		return api.ProjectRoles{}, fmt.Errorf("found no roles for %s", projectRoles.Name)
+	}
+
+	return projectRoles, nil
+}
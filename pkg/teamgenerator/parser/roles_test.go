@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestParseProjectRoles(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		record        []string
+		expectedRoles api.ProjectRoles
+		expectedError error
+	}{
+		{
+			name:          "roles in order",
+			record:        []string{"design", "manager", " builder", "presenter "},
+			expectedRoles: api.ProjectRoles{Name: "design", Roles: []string{"manager", "builder", "presenter"}},
+		},
+		{
+			name:          "empty columns skipped",
+			record:        []string{"design", "manager", "", "presenter"},
+			expectedRoles: api.ProjectRoles{Name: "design", Roles: []string{"manager", "presenter"}},
+		},
+		{
+			name:          "no roles",
+			record:        []string{"design"},
+			expectedError: errors.New(`expected all records in CSV roles file to contain a project and at least one role, record ["design"] contained 1 columns`),
+		},
+		{
+			name:          "only empty roles",
+			record:        []string{"design", " "},
+			expectedError: errors.New(`found no roles for design`),
+		},
+		{
+			name:          "missing name",
+			record:        []string{"", "manager"},
+			expectedError: errors.New(`found roles ["manager"] without a project name`),
+		},
+		{
+			name:          "repeated role",
+			record:        []string{"design", "manager", "manager"},
+			expectedError: errors.New(`found role "manager" more than once for design`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		actualRoles, actualError := parseProjectRoles(testCase.record)
+
+		if !reflect.DeepEqual(actualRoles, testCase.expectedRoles) {
+			t.Errorf("%s: correct roles not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedRoles, actualRoles)
+		}
+
+		if !reflect.DeepEqual(actualError, testCase.expectedError) {
+			t.Errorf("%s: correct error not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedError, actualError)
+		}
+	}
+}
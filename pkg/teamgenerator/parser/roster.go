@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
@@ -21,6 +22,12 @@ type csvRoster struct{}
 // This format is as follows:
 // Student ID, Student Name
 // [a-z0-9]+@duke.edu,"[\w\-],( [\w\-])+"
+//
+// The header row is optional. If it is present, any columns after the first
+// two name attributes of the students, like their major or year, and every
+// record must hold a value for each of them, which may be empty. A column
+// named "Section" holds the section of the class each student belongs to,
+// and columns named like "Skill: CAD" hold numeric scores for skills.
 func (r *csvRoster) Parse(inputFile string) ([]api.Student, error) {
 	file, err := os.Open(inputFile)
 	if err != nil {
@@ -33,9 +40,15 @@ func (r *csvRoster) Parse(inputFile string) ([]api.Student, error) {
 		return nil, fmt.Errorf("failed to read %q: %v", inputFile, err)
 	}
 
+	var attributes []string
+	if len(records) > 0 && isHeader(records[0]) {
+		attributes = records[0][2:]
+		records = records[1:]
+	}
+
 	roster := []api.Student{}
 	for _, record := range records {
-		student, err := parseStudent(record)
+		student, err := parseExtendedStudent(attributes, record)
 		if err != nil {
 			return nil, err
 		}
@@ -46,6 +59,59 @@ func (r *csvRoster) Parse(inputFile string) ([]api.Student, error) {
 	return roster, nil
 }
 
+// sectionColumn is the name of the column in an extended roster that holds the section of each student
+const sectionColumn = "Section"
+
+// skillPrefix starts the names of columns in an extended roster that hold a numeric score for a skill,
+// like "Skill: CAD"
+const skillPrefix = "Skill:"
+
+// isHeader determines if the record is the header row of an extended roster
+func isHeader(record []string) bool {
+	return len(record) >= 2 && strings.EqualFold(strings.TrimSpace(record[0]), "Student ID")
+}
+
+// parseExtendedStudent parses a record that holds a value for every attribute
+// after the two columns that identify the student
+func parseExtendedStudent(attributes []string, record []string) (api.Student, error) {
+	if len(record) != len(attributes)+2 {
+		return api.Student{}, fmt.Errorf("expected all records in CSV roster file to contain %d columns, record %q contained %d", len(attributes)+2, record, len(record))
+	}
+
+	student, err := parseStudent(record[:2])
+	if err != nil {
+		return api.Student{}, err
+	}
+
+	for i, attribute := range attributes {
+		value := strings.TrimSpace(record[i+2])
+		if len(value) == 0 {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(attribute), sectionColumn) {
+			student.Section = value
+			continue
+		}
+		if name := strings.TrimSpace(attribute); len(name) > len(skillPrefix) && strings.EqualFold(name[:len(skillPrefix)], skillPrefix) {
+			skill := strings.TrimSpace(name[len(skillPrefix):])
+			score, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return api.Student{}, fmt.Errorf("found malformed score %q for skill %s of %s, expected a number", value, skill, student.NetID)
+			}
+			if student.Skills == nil {
+				student.Skills = map[string]float64{}
+			}
+			student.Skills[skill] = score
+			continue
+		}
+		if student.Attributes == nil {
+			student.Attributes = map[string]string{}
+		}
+		student.Attributes[strings.TrimSpace(attribute)] = value
+	}
+	return student, nil
+}
+
 func parseStudent(record []string) (api.Student, error) {
 	if len(record) != 2 {
 		return api.Student{}, fmt.Errorf("expected all records in CSV roster file to contain two columns, record %q contained %d", record, len(record))
@@ -54,3 +54,85 @@ func TestParseStudent(t *testing.T) {
 		}
 	}
 }
+
+func TestParseExtendedStudent(t *testing.T) {
+	var testCases = []struct {
+		name            string
+		attributes      []string
+		record          []string
+		expectedStudent api.Student
+		expectedError   error
+	}{
+		{
+			name:            "no attributes",
+			record:          []string{"abc123@duke.edu", "LastName, FirstName"},
+			expectedStudent: api.Student{FullName: "FirstName LastName", NetID: "abc123@duke.edu"},
+		},
+		{
+			name:       "attributes",
+			attributes: []string{"Major", " Year "},
+			record:     []string{"abc123@duke.edu", "LastName, FirstName", "ME", "2"},
+			expectedStudent: api.Student{
+				FullName:   "FirstName LastName",
+				NetID:      "abc123@duke.edu",
+				Attributes: map[string]string{"Major": "ME", "Year": "2"},
+			},
+		},
+		{
+			name:       "section",
+			attributes: []string{"Major", "section"},
+			record:     []string{"abc123@duke.edu", "LastName, FirstName", "ME", "02"},
+			expectedStudent: api.Student{
+				FullName:   "FirstName LastName",
+				NetID:      "abc123@duke.edu",
+				Attributes: map[string]string{"Major": "ME"},
+				Section:    "02",
+			},
+		},
+		{
+			name:       "empty values are skipped",
+			attributes: []string{"Major", "Year"},
+			record:     []string{"abc123@duke.edu", "LastName, FirstName", "", "2"},
+			expectedStudent: api.Student{
+				FullName:   "FirstName LastName",
+				NetID:      "abc123@duke.edu",
+				Attributes: map[string]string{"Year": "2"},
+			},
+		},
+		{
+			name:       "skills",
+			attributes: []string{"Major", "Skill: CAD", "skill:Coding"},
+			record:     []string{"abc123@duke.edu", "LastName, FirstName", "ME", "3.5", ""},
+			expectedStudent: api.Student{
+				FullName:   "FirstName LastName",
+				NetID:      "abc123@duke.edu",
+				Attributes: map[string]string{"Major": "ME"},
+				Skills:     map[string]float64{"CAD": 3.5},
+			},
+		},
+		{
+			name:          "malformed skill score",
+			attributes:    []string{"Skill: CAD"},
+			record:        []string{"abc123@duke.edu", "LastName, FirstName", "high"},
+			expectedError: fmt.Errorf("found malformed score %q for skill %s of %s, expected a number", "high", "CAD", "abc123@duke.edu"),
+		},
+		{
+			name:          "missing attribute column",
+			attributes:    []string{"Major", "Year"},
+			record:        []string{"abc123@duke.edu", "LastName, FirstName", "ME"},
+			expectedError: fmt.Errorf("expected all records in CSV roster file to contain %d columns, record %q contained %d", 4, []string{"abc123@duke.edu", "LastName, FirstName", "ME"}, 3),
+		},
+	}
+
+	for _, testCase := range testCases {
+		actualStudent, actualError := parseExtendedStudent(testCase.attributes, testCase.record)
+
+		if !reflect.DeepEqual(actualStudent, testCase.expectedStudent) {
+			t.Errorf("%s: correct student record not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedStudent, actualStudent)
+		}
+
+		if !reflect.DeepEqual(actualError, testCase.expectedError) {
+			t.Errorf("%s: correct error not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedError, actualError)
+		}
+	}
+}
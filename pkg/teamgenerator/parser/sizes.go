@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+// NewCSVProjectSizes returns a new parser that can parse a CSV file into the sizes of groups for projects
+func NewCSVProjectSizes() ProjectSizes {
+	return &csvProjectSizes{}
+}
+
+type csvProjectSizes struct{}
+
+// Parse parses the sizes of groups from a CSV file with one project per record. This format is as follows:
+// Project Name, Size
+// .+,([0-9]+|[0-9]+-[0-9]+)
+// A size is either the optimal size of groups or a range of sizes every group must fall in.
+func (s *csvProjectSizes) Parse(inputFile string) ([]api.ProjectSize, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %q: %v", inputFile, err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %q: %v", inputFile, err)
+	}
+
+	sizes := []api.ProjectSize{}
+	for _, record := range records {
+		if len(record) != 2 {
+			return nil, fmt.Errorf("expected all records in CSV sizes file to contain two columns, record %q contained %d", record, len(record))
+		}
+
+		size, err := parseSize(strings.TrimSpace(record[0]), strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, err
+		}
+
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
+
+// ParseProjectSize parses a project name that may be followed by the size of its groups, like
+// "design", "design:3" or "final:2-4"
+func ParseProjectSize(project string) (api.ProjectSize, error) {
+	name, size, found := strings.Cut(project, ":")
+	if !found {
+		return api.ProjectSize{Name: name}, nil
+	}
+	return parseSize(name, size)
+}
+
+func parseSize(name, size string) (api.ProjectSize, error) {
+	if len(name) == 0 {
+		return api.ProjectSize{}, fmt.Errorf("found size %q without a project name", size)
+	}
+
+	if minimum, maximum, isRange := strings.Cut(size, "-"); isRange {
+		minSize, minErr := strconv.Atoi(minimum)
+		maxSize, maxErr := strconv.Atoi(maximum)
+		if minErr != nil || maxErr != nil || minSize < 1 || maxSize < minSize {
+			return api.ProjectSize{}, fmt.Errorf("found malformed range of sizes %q for %s, expected two positive sizes like 2-4", size, name)
+		}
+		return api.ProjectSize{Name: name, MinSize: minSize, MaxSize: maxSize}, nil
+	}
+
+	optimalSize, err := strconv.Atoi(size)
+	if err != nil || optimalSize < 1 {
+		return api.ProjectSize{}, fmt.Errorf("found malformed size %q for %s, expected a positive size like 3", size, name)
+	}
+	return api.ProjectSize{Name: name, OptimalSize: optimalSize}, nil
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
+)
+
+func TestParseProjectSize(t *testing.T) {
+	var testCases = []struct {
+		name          string
+		project       string
+		expectedSize  api.ProjectSize
+		expectedError error
+	}{
+		{
+			name:         "name only",
+			project:      "design",
+			expectedSize: api.ProjectSize{Name: "design"},
+		},
+		{
+			name:         "optimal size",
+			project:      "design:3",
+			expectedSize: api.ProjectSize{Name: "design", OptimalSize: 3},
+		},
+		{
+			name:         "range of sizes",
+			project:      "final:2-4",
+			expectedSize: api.ProjectSize{Name: "final", MinSize: 2, MaxSize: 4},
+		},
+		{
+			name:          "backwards range",
+			project:       "final:4-2",
+			expectedError: errors.New(`found malformed range of sizes "4-2" for final, expected two positive sizes like 2-4`),
+		},
+		{
+			name:          "malformed size",
+			project:       "design:three",
+			expectedError: errors.New(`found malformed size "three" for design, expected a positive size like 3`),
+		},
+		{
+			name:          "missing name",
+			project:       ":3",
+			expectedError: errors.New(`found size "3" without a project name`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		actualSize, actualError := ParseProjectSize(testCase.project)
+
+		if !reflect.DeepEqual(actualSize, testCase.expectedSize) {
+			t.Errorf("%s: correct size not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedSize, actualSize)
+		}
+
+		if !reflect.DeepEqual(actualError, testCase.expectedError) {
+			t.Errorf("%s: correct error not created:\n\twanted:\n\t%v\n\tgot:\n\t%v", testCase.name, testCase.expectedError, actualError)
+		}
+	}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/api"
 	"github.com/stevekuznetsov/engineering-innovation-utils/pkg/teamgenerator/generator"
@@ -24,58 +29,785 @@ var (
 	// initialize the grouping algorithm with prior groupings
 	priorGroupingFiles string
 
+	// priorWeights is a comma-delimited list of how much repeating a collaboration
+	// from each of the prior groupings costs, in the same order as the files
+	priorWeights string
+
+	// priorDecay is the factor by which every prior grouping costs less than the
+	// one after it, with the files ordered from oldest to newest
+	priorDecay float64
+
+	// pinnedGroupingFiles is a comma-delimited list of JSON files containing
+	// partial groupings for projects, whose groups are kept as they are
+	pinnedGroupingFiles string
+
+	// repairFile is a JSON file containing a published project grouping that
+	// should be repaired for the current roster instead of generating new ones
+	repairFile string
+
+	// sizesFile is a CSV file containing the sizes of groups for projects,
+	// overriding the default optimal group size
+	sizesFile string
+
+	// rolesFile is a CSV file containing the roles members of groups take on
+	// for projects
+	rolesFile string
+
 	// rosterFile is a CSV file containing the roster of the class
 	rosterFile string
+
+	// seed is the seed for the random source used to generate groupings. If
+	// it is not set, a random seed is chosen and recorded in the output
+	seed int64
+
+	// timeout is the wall-clock time the search for groupings may take
+	timeout time.Duration
+
+	// attempts is the number of attempts the search for groupings may make
+	attempts int
+
+	// strategy is the name of the strategy used to search for groupings
+	strategy string
+
+	// schedule is the cooling schedule used by the annealing strategy
+	schedule = generator.DefaultSchedule()
+
+	// workers is the number of independent generations to run in parallel
+	workers int
+
+	// constraintsFile is a CSV file containing rules about which students must
+	// be kept together or apart
+	constraintsFile string
+
+	// fairnessWeight is how much spreading unavoidable repeats fairly across
+	// students matters compared to avoiding repairings
+	fairnessWeight float64
+
+	// sizeRotationWeight is how much giving odd-sized groups to students who
+	// haven't had one matters compared to avoiding repairings
+	sizeRotationWeight float64
+
+	// preferencesFile is a CSV file containing who students would like to work
+	// with and who they would like not to work with
+	preferencesFile string
+
+	// preferenceWeight is how much honoring a preference matters compared to
+	// avoiding a repairing
+	preferenceWeight float64
+
+	// availabilityFile is a CSV weekly grid of the hours students are free to
+	// meet with their groups
+	availabilityFile string
+
+	// minSharedHours is the number of hours in the week every group should
+	// have when all of its members are free
+	minSharedHours int
+
+	// availabilityWeight is how much every hour a group is short of the shared
+	// hours matters compared to avoiding repairings
+	availabilityWeight float64
+
+	// skillBalanceWeight is how much giving every group a mix of skills like
+	// the class's matters compared to avoiding repairings
+	skillBalanceWeight float64
+
+	// balancedSkills is a comma-delimited list of the skills to balance across
+	// groups, or empty for every skill on the roster
+	balancedSkills string
+
+	// spreadAttributes is a comma-delimited list of attributes whose values
+	// should be spread as evenly as possible across groups
+	spreadAttributes string
+
+	// spreadWeight is how much spreading attributes matters compared to
+	// avoiding repairings
+	spreadWeight float64
+
+	// isolatedAttributes is a comma-delimited list of attributes, optionally
+	// as attribute=value, that no student should be alone in their group to hold
+	isolatedAttributes string
+
+	// isolationWeight is how much avoiding isolated students matters compared
+	// to avoiding repairings
+	isolationWeight float64
+
+	// statsFormat is the format of the report printed by the stats command
+	statsFormat string
+
+	// explain determines if how every student ended up in their group is
+	// recorded and printed
+	explain bool
 )
 
 const (
 	defaultOptimalGroupSize    = 3
 	defaultPreferSmallerGroups = false
+
+	// reshuffleStrategy grows groups one student at a time, reshuffling students when stuck
+	reshuffleStrategy = "reshuffle"
+
+	// annealStrategy improves a random grouping by swapping students using simulated annealing
+	annealStrategy = "anneal"
+
+	// designStrategy builds groupings from a known repeat-free design, reshuffling when none is known
+	designStrategy = "design"
+
+	// exactStrategy searches every grouping of a small class for one with the fewest repairings
+	exactStrategy = "exact"
+
+	// validateCommand checks groupings for mistakes instead of generating them
+	validateCommand = "validate"
+
+	// statsCommand reports how students collaborate in groupings instead of generating them
+	statsCommand = "stats"
+
+	// textFormat and jsonFormat are the formats the stats command can print its report in
+	textFormat = "text"
+	jsonFormat = "json"
 )
 
 func init() {
 	flag.IntVar(&optimalGroupSize, "size", defaultOptimalGroupSize, "optimal group size")
 	flag.BoolVar(&preferSmallerGroups, "smaller-groups", defaultPreferSmallerGroups, "prefer smaller groups")
 	flag.StringVar(&priorGroupingFiles, "priors", "", "comma-delimited list of files containing prior groupings")
+	flag.StringVar(&priorWeights, "prior-weights", "", "comma-delimited list of how much repeating a collaboration from each prior grouping costs, in the same order as -priors (default: 1 each)")
+	flag.Float64Var(&priorDecay, "prior-decay", 0, "factor by which each prior grouping costs less than the next, with -priors ordered oldest to newest (default: no decay)")
+	flag.StringVar(&pinnedGroupingFiles, "pinned", "", "comma-delimited list of files containing partial groupings whose groups are kept as they are")
+	flag.StringVar(&repairFile, "repair", "", "file containing a published grouping to repair for the current roster, moving as few students as possible")
+	flag.StringVar(&sizesFile, "sizes", "", "CSV file containing the optimal size or range of sizes of groups for projects")
+	flag.StringVar(&rolesFile, "roles", "", "CSV file containing the roles members of groups take on for projects, rotated across projects")
 	flag.StringVar(&rosterFile, "roster", "", "CSV file containing class roster")
+	flag.Int64Var(&seed, "seed", 0, "seed for the random source, to reproduce a prior run (default: random)")
+	flag.DurationVar(&timeout, "timeout", 0, fmt.Sprintf("stop searching for better groupings after this long (default: no limit, or %s for the %q strategy)", generator.DefaultExactTimeout, exactStrategy))
+	flag.IntVar(&attempts, "attempts", 0, "stop searching for better groupings after this many attempts (default: no limit)")
+	flag.StringVar(&strategy, "strategy", reshuffleStrategy, fmt.Sprintf("strategy used to search for groupings, one of %q, %q, %q or %q", reshuffleStrategy, annealStrategy, designStrategy, exactStrategy))
+	flag.Float64Var(&schedule.InitialTemperature, "anneal-temperature", schedule.InitialTemperature, "initial temperature for the annealing strategy")
+	flag.Float64Var(&schedule.CoolingRate, "anneal-cooling-rate", schedule.CoolingRate, "factor by which the annealing strategy cools the temperature")
+	flag.Float64Var(&schedule.MinimumTemperature, "anneal-min-temperature", schedule.MinimumTemperature, "temperature at which the annealing strategy stops")
+	flag.IntVar(&schedule.SwapsPerTemperature, "anneal-swaps", schedule.SwapsPerTemperature, "swaps the annealing strategy tries at every temperature")
+	flag.StringVar(&constraintsFile, "constraints", "", "CSV file containing rules about which students must be kept together or apart")
+	flag.Float64Var(&fairnessWeight, "fairness", 0, "weight of spreading unavoidable repeats fairly across students compared to avoiding repairings (default: off)")
+	flag.Float64Var(&sizeRotationWeight, "size-rotation", 0, "weight of giving places in odd-sized groups to students who haven't had one compared to avoiding repairings (default: off)")
+	flag.StringVar(&preferencesFile, "preferences", "", "CSV file containing who students would like to work with and who they would like not to")
+	flag.Float64Var(&preferenceWeight, "preference-weight", 1, "weight of honoring preferences compared to avoiding repairings")
+	flag.StringVar(&availabilityFile, "availability", "", "CSV weekly grid of the hours students are free to meet, with a header row like Student ID,Mon 09:00,Mon 10:00")
+	flag.IntVar(&minSharedHours, "min-shared-hours", 2, "hours in the week every group should have when all of its members are free")
+	flag.Float64Var(&availabilityWeight, "availability-weight", 10, "weight of every hour a group is short of the shared hours compared to avoiding repairings, which only -strategy anneal trades off; other strategies use it to choose between students")
+	flag.Float64Var(&skillBalanceWeight, "balance-skills", 0, "weight of giving every group an average and spread of skill scores like the class's compared to avoiding repairings, which only -strategy anneal trades off and seeds with a snake draft; other strategies use it to choose between students (default: off)")
+	flag.StringVar(&balancedSkills, "skills", "", "comma-delimited list of the skills to balance across groups (default: every skill on the roster)")
+	flag.StringVar(&spreadAttributes, "spread", "", "comma-delimited list of roster attributes to spread evenly across groups")
+	flag.Float64Var(&spreadWeight, "spread-weight", 1, "weight of spreading attributes compared to avoiding repairings")
+	flag.StringVar(&isolatedAttributes, "no-isolation", "", "comma-delimited list of roster attributes, or attribute=value, that no student should hold alone in their group")
+	flag.Float64Var(&isolationWeight, "isolation-weight", 10, "weight of avoiding isolated students compared to avoiding repairings")
+	flag.IntVar(&workers, "workers", 1, "number of independent generations to run in parallel, keeping the best (0 uses every CPU)")
+	flag.BoolVar(&explain, "explain", false, "explain how every student ended up in their group, including which prior collaborations they repeat")
+	flag.StringVar(&statsFormat, "format", textFormat, fmt.Sprintf("format of the report printed by the %s command, %q or %q", statsCommand, textFormat, jsonFormat))
 }
 
 func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: teamgenerator [flags] project...\n       teamgenerator %s [flags] grouping.json...\n       teamgenerator %s [flags] grouping.json...\n", validateCommand, statsCommand)
+		flag.PrintDefaults()
+	}
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case validateCommand:
+			flag.CommandLine.Parse(os.Args[2:])
+			validateGroupings(flag.Args())
+			return
+		case statsCommand:
+			flag.CommandLine.Parse(os.Args[2:])
+			reportStats(flag.Args())
+			return
+		}
+	}
+
 	flag.Parse()
-	projectNames := flag.Args()
-	if len(projectNames) < 1 {
+	if flag.NArg() < 1 && len(repairFile) == 0 {
 		fmt.Fprintln(os.Stderr, "teamgenerator requires at least one project name to create groups for")
 		os.Exit(1)
 	}
 
-	fmt.Fprintf(os.Stdout, "generating teams for the following projects: %v\n", projectNames)
+	// projects may be given as name:size or name:min-max to override the sizes of their groups
+	var projectNames []string
+	projectSizes := parseProjectSizes()
+	for _, project := range flag.Args() {
+		size, err := parser.ParseProjectSize(project)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse project: %v\n", err)
+			os.Exit(1)
+		}
+		projectNames = append(projectNames, size.Name)
+		projectSizes = append(projectSizes, size)
+	}
 
-	roster, err := parser.NewCSVRoster().Parse(rosterFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to parse roster file: %v\n", err)
-		os.Exit(1)
+	if len(repairFile) == 0 {
+		fmt.Fprintf(os.Stdout, "generating teams for the following projects: %v\n", projectNames)
 	}
 
-	generator := generator.NewClassGrouping(optimalGroupSize, preferSmallerGroups)
+	roster := parseRoster()
 
-	var grouping api.ClassGrouping
-	if len(priorGroupingFiles) > 0 {
-		var priors []api.ProjectGrouping
-		for _, file := range strings.Split(priorGroupingFiles, ",") {
-			prior, err := parser.NewJSONProject().Parse(file)
+	// the bound only holds when every project uses the default group size
+	defaultSizes := true
+	for _, size := range projectSizes {
+		if size.OptimalSize > 0 || size.MaxSize > 0 {
+			defaultSizes = false
+		}
+	}
+	// a repaired grouping is for a project that was already grouped, so the bound doesn't apply
+	if maxProjects := maxRepeatFreeProjects(roster); maxProjects >= 0 && defaultSizes && len(repairFile) == 0 {
+		fmt.Fprintf(os.Stdout, "repeat collaborations can't be avoided for this class beyond %d projects, and may be needed sooner\n", maxProjects)
+	}
+
+	options := []generator.Option{generator.WithProjectSizes(projectSizes)}
+	if flagWasSet("seed") {
+		options = append(options, generator.WithSeed(seed))
+	}
+	if len(rolesFile) > 0 {
+		roles, err := parser.NewCSVProjectRoles().Parse(rolesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse roles file: %v\n", err)
+			os.Exit(1)
+		}
+		options = append(options, generator.WithProjectRoles(roles))
+	}
+	if len(constraintsFile) > 0 {
+		options = append(options, generator.WithConstraints(parseConstraints()))
+	}
+	if len(priorWeights) > 0 {
+		var weights []float64
+		for _, value := range splitList(priorWeights) {
+			weight, err := strconv.ParseFloat(value, 64)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "failed to pase prior grouping file: %v\n", err)
+				fmt.Fprintf(os.Stderr, "failed to parse prior weight %q: %v\n", value, err)
 				os.Exit(1)
 			}
-			priors = append(priors, prior)
+			weights = append(weights, weight)
+		}
+		if numPriors := len(splitList(priorGroupingFiles)); len(weights) != numPriors {
+			fmt.Fprintf(os.Stderr, "expected a weight for each of the %d prior groupings, got %d\n", numPriors, len(weights))
+			os.Exit(1)
 		}
-		grouping = generator.GenerateWithPriors(roster, priors, projectNames)
+		options = append(options, generator.WithPriorWeights(weights))
+	}
+	if priorDecay > 0 {
+		options = append(options, generator.WithPriorDecay(priorDecay))
+	}
+	if len(pinnedGroupingFiles) > 0 {
+		options = append(options, generator.WithPinnedGroups(parsePinned()))
+	}
+	// soft objectives are traded off against repairings, which only the annealing strategy does freely
+	softObjectives := false
+	if fairnessWeight > 0 {
+		options = append(options, generator.WithFairness(fairnessWeight))
+		softObjectives = true
+	}
+	if sizeRotationWeight > 0 {
+		options = append(options, generator.WithSizeRotation(sizeRotationWeight))
+		softObjectives = true
+	}
+	if len(preferencesFile) > 0 {
+		preferences, err := parser.NewCSVPreferences().Parse(preferencesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse preferences file: %v\n", err)
+			os.Exit(1)
+		}
+		options = append(options, generator.WithPreferences(preferences, preferenceWeight))
+		softObjectives = true
+	}
+	var availability []api.Availability
+	if len(availabilityFile) > 0 {
+		var err error
+		availability, err = parser.NewCSVAvailability().Parse(availabilityFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse availability file: %v\n", err)
+			os.Exit(1)
+		}
+		options = append(options, generator.WithAvailability(availability, minSharedHours, availabilityWeight))
+		softObjectives = true
+	}
+	if skillBalanceWeight > 0 {
+		options = append(options, generator.WithSkillBalance(splitList(balancedSkills), skillBalanceWeight))
+		softObjectives = true
+	}
+	if explain {
+		options = append(options, generator.WithExplanations())
+	}
+	for _, attribute := range splitList(spreadAttributes) {
+		options = append(options, generator.WithSpreadAttribute(attribute, spreadWeight))
+		softObjectives = true
+	}
+	for _, attribute := range splitList(isolatedAttributes) {
+		name, value, _ := strings.Cut(attribute, "=")
+		options = append(options, generator.WithNoIsolation(name, value, isolationWeight))
+		softObjectives = true
+	}
+	priors := parsePriors()
+	if len(repairFile) > 0 {
+		repairGrouping(roster, priors, options)
+		return
+	}
+
+	var newClassGrouping func(opts ...generator.Option) generator.ClassGrouping
+	switch strategy {
+	case reshuffleStrategy:
+		newClassGrouping = func(opts ...generator.Option) generator.ClassGrouping {
+			return generator.NewClassGrouping(optimalGroupSize, preferSmallerGroups, opts...)
+		}
+	case annealStrategy:
+		newClassGrouping = func(opts ...generator.Option) generator.ClassGrouping {
+			return generator.NewAnnealingClassGrouping(optimalGroupSize, preferSmallerGroups, schedule, opts...)
+		}
+	case designStrategy:
+		newClassGrouping = func(opts ...generator.Option) generator.ClassGrouping {
+			fallback := generator.NewClassGrouping(optimalGroupSize, preferSmallerGroups, opts...)
+			return generator.NewDesignClassGrouping(optimalGroupSize, preferSmallerGroups, fallback, opts...)
+		}
+	case exactStrategy:
+		newClassGrouping = func(opts ...generator.Option) generator.ClassGrouping {
+			return generator.NewExactClassGrouping(optimalGroupSize, preferSmallerGroups, opts...)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown strategy %q, expected %q, %q, %q or %q\n", strategy, reshuffleStrategy, annealStrategy, designStrategy, exactStrategy)
+		os.Exit(1)
+	}
+
+	if softObjectives && (strategy == reshuffleStrategy || strategy == designStrategy) {
+		fmt.Fprintf(os.Stderr, "warning: the %s strategy avoids repairings before anything else and only uses the other objectives to choose between students, use -strategy %s to trade them off against repairings\n", strategy, annealStrategy)
+	}
+
+	groupGenerator := newClassGrouping(options...)
+	if workers != 1 {
+		groupGenerator = generator.NewParallelClassGrouping(workers, newClassGrouping, options...)
+	}
+
+	// an interrupt stops the search, leaving us with the best grouping found so far
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	budget := generator.Budget{Timeout: timeout, Attempts: attempts}
+
+	var grouping api.ClassGrouping
+	var err error
+	if len(priors) > 0 {
+		grouping, err = groupGenerator.GenerateWithPriorsContext(ctx, roster, priors, projectNames, budget)
 	} else {
-		grouping = generator.Generate(roster, projectNames)
+		grouping, err = groupGenerator.GenerateContext(ctx, roster, projectNames, budget)
+	}
+
+	exitCode := 0
+	if err != nil {
+		partial, ok := err.(*generator.PartialGroupingError)
+		if !ok || !partial.Found() {
+			fmt.Fprintf(os.Stderr, "failed to generate class grouping: %v\n", err)
+			os.Exit(1)
+		}
+		// the grouping is still usable, but we want scripts to know it was not fully optimized
+		fmt.Fprintf(os.Stderr, "warning: groupings were only partially optimized: %v\n", err)
+		exitCode = 2
 	}
 
+	for i, worker := range grouping.Summary.Workers {
+		fmt.Fprintf(os.Stdout, "worker %d (seed %d) made %d attempts and found a grouping with %d repairings and a score of %g\n", i, worker.Seed, worker.Attempts, worker.Repairings, worker.Score)
+	}
+	if strategy == exactStrategy {
+		if grouping.Summary.Optimal {
+			fmt.Fprintf(os.Stdout, "proved that no grouping can have fewer than %d repairings\n", grouping.Summary.Repairings)
+		} else {
+			fmt.Fprintf(os.Stdout, "stopped before proving the grouping optimal, no grouping can have fewer than %d repairings\n", grouping.Summary.LowerBound)
+		}
+	}
+	if len(grouping.Summary.Repeats) > 0 {
+		most := 0
+		for _, repeats := range grouping.Summary.Repeats {
+			most = max(most, repeats)
+		}
+		fmt.Fprintf(os.Stdout, "%d students have repeated collaborations, at most %d each\n", len(grouping.Summary.Repeats), most)
+	}
+	if len(grouping.Summary.Satisfaction) > 0 {
+		honored, requested := 0, 0
+		for _, satisfaction := range grouping.Summary.Satisfaction {
+			honored += satisfaction.Honored
+			requested += satisfaction.Requested
+		}
+		fmt.Fprintf(os.Stdout, "honored %d of %d requests from %d students\n", honored, requested, len(grouping.Summary.Satisfaction))
+	}
+	if len(grouping.Summary.Skills) > 0 {
+		printSkills(grouping.Summary.Skills)
+	}
+	if len(availability) > 0 {
+		printMeetings(grouping, availability)
+	}
+	if len(grouping.Summary.Explanations) > 0 {
+		printExplanations(grouping.Summary.Explanations)
+	}
+	fmt.Fprintf(os.Stdout, "generated teams using seed %d\n", grouping.Seed)
+
 	if err := json.NewEncoder(os.Stdout).Encode(&grouping); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to encode class grouping: %v\n", err)
 		os.Exit(1)
 	}
+	os.Exit(exitCode)
+}
+
+// parseRoster parses the roster of the class from its file
+func parseRoster() []api.Student {
+	roster, err := parser.NewCSVRoster().Parse(rosterFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse roster file: %v\n", err)
+		os.Exit(1)
+	}
+	return roster
+}
+
+// parseProjectSizes parses the sizes of groups for projects from their file, if one was given
+func parseProjectSizes() []api.ProjectSize {
+	if len(sizesFile) == 0 {
+		return nil
+	}
+	sizes, err := parser.NewCSVProjectSizes().Parse(sizesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse sizes file: %v\n", err)
+		os.Exit(1)
+	}
+	return sizes
+}
+
+// parseConstraints parses the rules about which students must be kept together or apart from their file
+func parseConstraints() []api.Constraint {
+	constraints, err := parser.NewCSVConstraints().Parse(constraintsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse constraints file: %v\n", err)
+		os.Exit(1)
+	}
+	return constraints
+}
+
+// parsePinned parses the partial groupings whose groups are kept as they are from their files
+func parsePinned() []api.ProjectGrouping {
+	var pinned []api.ProjectGrouping
+	for _, file := range splitList(pinnedGroupingFiles) {
+		grouping, err := parser.NewJSONProject().Parse(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse pinned grouping file: %v\n", err)
+			os.Exit(1)
+		}
+		pinned = append(pinned, grouping)
+	}
+	return pinned
+}
+
+// parsePriors parses the prior groupings from their files
+func parsePriors() []api.ProjectGrouping {
+	var priors []api.ProjectGrouping
+	for _, file := range splitList(priorGroupingFiles) {
+		prior, err := parser.NewJSONProject().Parse(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to pase prior grouping file: %v\n", err)
+			os.Exit(1)
+		}
+		priors = append(priors, prior)
+	}
+	return priors
+}
+
+// repairGrouping repairs the published grouping for the roster, printing who had to move
+func repairGrouping(roster []api.Student, priors []api.ProjectGrouping, options []generator.Option) {
+	existing, err := parser.NewJSONProject().Parse(repairFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse grouping file to repair: %v\n", err)
+		os.Exit(1)
+	}
+
+	grouping, moves, renumbered, err := generator.NewRepairer(optimalGroupSize, preferSmallerGroups, options...).Repair(existing, roster, priors)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to repair grouping: %v\n", err)
+		os.Exit(1)
+	}
+
+	moved, joined, dropped := 0, 0, 0
+	for _, move := range moves {
+		switch {
+		case move.From < 0:
+			joined++
+		case move.To < 0:
+			dropped++
+		default:
+			moved++
+		}
+	}
+	fmt.Fprintf(os.Stdout, "repaired groups for %s by moving %d students, after %d joined and %d dropped out\n", grouping.Name, moved, joined, dropped)
+	for _, renumbering := range renumbered {
+		fmt.Fprintln(os.Stdout, renumbering)
+	}
+	for _, move := range moves {
+		fmt.Fprintln(os.Stdout, move)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(&grouping); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode project grouping: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// validateGroupings checks the groupings in every file against the roster, printing every violation found. Group
+// sizes, constraints, pinned groups and prior groupings are given with the same flags used to generate groupings.
+func validateGroupings(files []string) {
+	if len(files) < 1 {
+		fmt.Fprintf(os.Stderr, "teamgenerator %s requires at least one grouping file to check\n", validateCommand)
+		os.Exit(1)
+	}
+
+	roster := parseRoster()
+	options := []generator.Option{generator.WithProjectSizes(parseProjectSizes())}
+	if len(constraintsFile) > 0 {
+		options = append(options, generator.WithConstraints(parseConstraints()))
+	}
+	if len(pinnedGroupingFiles) > 0 {
+		options = append(options, generator.WithPinnedGroups(parsePinned()))
+	}
+	validator := generator.NewValidator(optimalGroupSize, preferSmallerGroups, options...)
+	priors := parsePriors()
+
+	valid := true
+	for _, file := range files {
+		grouping, err := parser.NewJSONClassGrouping().Parse(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse grouping file: %v\n", err)
+			os.Exit(1)
+		}
+
+		violations := validator.Validate(roster, grouping, priors)
+		for _, violation := range violations {
+			fmt.Fprintf(os.Stdout, "%s: %s\n", file, violation)
+		}
+		if len(violations) > 0 {
+			valid = false
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "%s: found no violations in %d projects\n", file, len(grouping.Projects))
+	}
+	if !valid {
+		os.Exit(1)
+	}
+}
+
+// reportStats prints how the students on the roster collaborate across the prior groupings and the groupings in every
+// file, in the format that was asked for
+func reportStats(files []string) {
+	if len(files) < 1 {
+		fmt.Fprintf(os.Stderr, "teamgenerator %s requires at least one grouping file to report on\n", statsCommand)
+		os.Exit(1)
+	}
+	if statsFormat != textFormat && statsFormat != jsonFormat {
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected %q or %q\n", statsFormat, textFormat, jsonFormat)
+		os.Exit(1)
+	}
+
+	roster := parseRoster()
+	groupings := parsePriors()
+	for _, file := range files {
+		grouping, err := parser.NewJSONClassGrouping().Parse(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse grouping file: %v\n", err)
+			os.Exit(1)
+		}
+		groupings = append(groupings, grouping.Projects...)
+	}
+
+	stats := generator.Stats(roster, groupings)
+	if statsFormat == jsonFormat {
+		if err := json.NewEncoder(os.Stdout).Encode(&stats); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode stats: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	printStats(stats)
+}
+
+// printStats prints the collaboration matrix, the repeats every student has, how many of their classmates every
+// student has worked with and the sizes of the groups for every project
+func printStats(stats api.Stats) {
+	nameWidth, countWidth := 0, 1
+	for i, row := range stats.Collaborations {
+		nameWidth = max(nameWidth, len(stats.Students[i]))
+		for _, count := range row {
+			countWidth = max(countWidth, len(strconv.Itoa(count)))
+		}
+	}
+	fmt.Fprintf(os.Stdout, "collaborations between %d students, with columns in the same order as the rows:\n", len(stats.Students))
+	for i, row := range stats.Collaborations {
+		cells := make([]string, len(row))
+		for j, count := range row {
+			switch {
+			case i == j:
+				cells[j] = fmt.Sprintf("%*s", countWidth, "-")
+			case count == 0:
+				cells[j] = fmt.Sprintf("%*s", countWidth, ".")
+			default:
+				cells[j] = fmt.Sprintf("%*d", countWidth, count)
+			}
+		}
+		fmt.Fprintf(os.Stdout, "%-*s %s\n", nameWidth, stats.Students[i], strings.Join(cells, " "))
+	}
+
+	fmt.Fprintf(os.Stdout, "%d repairings, %d students have repeated collaborations\n", stats.Repairings, len(stats.Repeats))
+	repeated := make([]string, 0, len(stats.Repeats))
+	for student := range stats.Repeats {
+		repeated = append(repeated, student)
+	}
+	sort.Slice(repeated, func(i, j int) bool {
+		if stats.Repeats[repeated[i]] != stats.Repeats[repeated[j]] {
+			return stats.Repeats[repeated[i]] > stats.Repeats[repeated[j]]
+		}
+		return repeated[i] < repeated[j]
+	})
+	for _, student := range repeated {
+		fmt.Fprintf(os.Stdout, "  %s repeated %d collaborations\n", student, stats.Repeats[student])
+	}
+
+	var covered, classmates int
+	for _, coverage := range stats.Coverage {
+		covered, classmates = covered+coverage.Partners, classmates+coverage.Classmates
+	}
+	if classmates > 0 {
+		fmt.Fprintf(os.Stdout, "students worked with %.0f%% of their classmates\n", 100*float64(covered)/float64(classmates))
+	}
+	for _, coverage := range stats.Coverage {
+		fmt.Fprintf(os.Stdout, "  %s worked with %d of %d classmates\n", coverage.Student, coverage.Partners, coverage.Classmates)
+	}
+
+	for _, sizes := range stats.GroupSizes {
+		var counts []int
+		for size := range sizes.Counts {
+			counts = append(counts, size)
+		}
+		sort.Ints(counts)
+		var descriptions []string
+		for _, size := range counts {
+			groups := "groups"
+			if sizes.Counts[size] == 1 {
+				groups = "group"
+			}
+			descriptions = append(descriptions, fmt.Sprintf("%d %s of %d", sizes.Counts[size], groups, size))
+		}
+		fmt.Fprintf(os.Stdout, "%s has %s\n", sizes.Project, strings.Join(descriptions, ", "))
+	}
+}
+
+// printSkills prints the range of the groups' average scores for every skill in every project
+func printSkills(profiles []api.SkillProfile) {
+	type skillRange struct {
+		project, skill string
+	}
+	var ranges []skillRange
+	lowest, highest := map[skillRange]float64{}, map[skillRange]float64{}
+	for _, profile := range profiles {
+		var skills []string
+		for skill := range profile.Mean {
+			skills = append(skills, skill)
+		}
+		sort.Strings(skills)
+		for _, skill := range skills {
+			key, mean := skillRange{project: profile.Project, skill: skill}, profile.Mean[skill]
+			if _, seen := lowest[key]; !seen {
+				ranges = append(ranges, key)
+				lowest[key], highest[key] = mean, mean
+			}
+			lowest[key], highest[key] = min(lowest[key], mean), max(highest[key], mean)
+		}
+	}
+
+	for _, key := range ranges {
+		fmt.Fprintf(os.Stdout, "average %s scores of groups for %s range from %g to %g\n", key.skill, key.project, lowest[key], highest[key])
+	}
+}
+
+// printExplanations prints how every student ended up in their group, student by student and project by project
+func printExplanations(explanations []api.Explanation) {
+	byStudent := map[string][]api.Explanation{}
+	var students []string
+	for _, explanation := range explanations {
+		if _, seen := byStudent[explanation.Student]; !seen {
+			students = append(students, explanation.Student)
+		}
+		byStudent[explanation.Student] = append(byStudent[explanation.Student], explanation)
+	}
+	sort.Strings(students)
+
+	for _, student := range students {
+		fmt.Fprintf(os.Stdout, "%s:\n", student)
+		for _, explanation := range byStudent[student] {
+			fmt.Fprintf(os.Stdout, "  for %s:\n", explanation.Project)
+			for _, line := range explanation.Narrative {
+				fmt.Fprintf(os.Stdout, "    %s\n", line)
+			}
+		}
+	}
+}
+
+// printMeetings prints the windows in the week every group can meet in, for the groups where any member gave
+// their availability
+func printMeetings(grouping api.ClassGrouping, availability []api.Availability) {
+	known := map[string]bool{}
+	for _, studentAvailability := range availability {
+		known[studentAvailability.Student] = true
+	}
+
+	for _, project := range grouping.Projects {
+		for i, group := range project.Groups {
+			anyKnown := false
+			for _, member := range group.Members {
+				anyKnown = anyKnown || known[member.NetID]
+			}
+			if !anyKnown {
+				continue
+			}
+
+			meetings := "no common free time"
+			if len(group.Meetings) > 0 {
+				meetings = strings.Join(group.Meetings, ", ")
+			}
+			fmt.Fprintf(os.Stdout, "group %d for %s can meet: %s\n", i+1, project.Name, meetings)
+		}
+	}
+}
+
+// maxRepeatFreeProjects determines an upper bound on the projects that can be grouped without repeat
+// collaborations in every section of the class, or -1 if there is no limit
+func maxRepeatFreeProjects(roster []api.Student) int {
+	sectionSizes := map[string]int{}
+	for _, student := range roster {
+		sectionSizes[student.Section]++
+	}
+
+	maxProjects := -1
+	for _, size := range sectionSizes {
+		if projects := generator.MaxRepeatFreeProjects(size, optimalGroupSize, preferSmallerGroups); projects >= 0 && (maxProjects < 0 || projects < maxProjects) {
+			maxProjects = projects
+		}
+	}
+	return maxProjects
+}
+
+// splitList splits a comma-delimited list, ignoring empty items
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// flagWasSet determines if the flag with the given name was set on the command line
+func flagWasSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }